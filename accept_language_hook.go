@@ -0,0 +1,101 @@
+package httpagent
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type languageContextKeyType struct{}
+
+var languageContextKey = languageContextKeyType{}
+
+// ContextWithLanguage sets the language tags in priority order.
+func ContextWithLanguage(ctx context.Context, tags ...string) context.Context {
+	return context.WithValue(ctx, languageContextKey, tags)
+}
+
+func contextLanguage(ctx context.Context) []string {
+	tags, _ := ctx.Value(languageContextKey).([]string)
+	return tags
+}
+
+type AcceptLanguageHook struct {
+	Default []string
+}
+
+func (h *AcceptLanguageHook) Do(req *http.Request) error {
+	if _, ok := req.Header["Accept-Language"]; ok {
+		return nil
+	}
+
+	tags := contextLanguage(req.Context())
+	if len(tags) == 0 {
+		tags = h.Default
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	req.Header.Set("Accept-Language", formatAcceptLanguage(tags))
+	return nil
+}
+
+func formatAcceptLanguage(tags []string) string {
+	values := make([]string, len(tags))
+	lowest := 1000 // the lowest weight so far in thousandths
+	for i, tag := range tags {
+		if q, ok := tagQuality(tag); ok {
+			values[i] = tag
+			if q >= 0 && q < lowest {
+				lowest = q
+			}
+			continue
+		}
+		if i == 0 {
+			values[i] = tag
+			continue
+		}
+
+		// decrease the weight by priority, and keep it below the weights of the preceding tags
+		q := 1000 - 100*i
+		if q < 100 {
+			q = 100
+		}
+		if q >= lowest {
+			q = lowest - 100
+			if q < 100 {
+				q = 100
+			}
+			if q > lowest {
+				q = lowest
+			}
+		}
+		lowest = q
+		values[i] = tag + ";q=" + strconv.FormatFloat(float64(q)/1000, 'f', -1, 64)
+	}
+	return strings.Join(values, ", ")
+}
+
+// tagQuality returns the weight of the tag in thousandths if it already carries one. (e.g. "en;q=0.5")
+// The weight is -1 if it is malformed.
+func tagQuality(tag string) (int, bool) {
+	for _, param := range strings.Split(tag, ";")[1:] {
+		name, value := strings.TrimSpace(param), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+1:])
+		}
+		if !strings.EqualFold(name, "q") {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(value, 64)
+		if err != nil || q < 0 || q > 1 {
+			return -1, true
+		}
+		return int(math.Round(q * 1000)), true
+	}
+	return 0, false
+}
@@ -0,0 +1,84 @@
+package httpagent
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAcceptLanguageHook(t *testing.T) {
+	hook := &AcceptLanguageHook{Default: []string{"en"}}
+
+	cases := []struct {
+		name     string
+		tags     []string
+		header   string
+		expected string
+	}{
+		{name: "Single", tags: []string{"ja"}, expected: "ja"},
+		{name: "Weighted", tags: []string{"ja", "en-US", "en"}, expected: "ja, en-US;q=0.9, en;q=0.8"},
+		{name: "AlreadyWeighted", tags: []string{"ja", "en-US;q=0.5", "en; Q=0.3", "fr", "de"}, expected: "ja, en-US;q=0.5, en; Q=0.3, fr;q=0.2, de;q=0.1"},
+		{name: "LowWeighted", tags: []string{"ja;q=0.05", "en"}, expected: "ja;q=0.05, en;q=0.05"},
+		{name: "Default", expected: "en"},
+		{name: "Exists", tags: []string{"ja"}, header: "fr", expected: "fr"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+			if tc.tags != nil {
+				req = req.WithContext(ContextWithLanguage(req.Context(), tc.tags...))
+			}
+			if tc.header != "" {
+				req.Header.Set("Accept-Language", tc.header)
+			}
+
+			err := hook.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v := req.Header.Get("Accept-Language"); v != tc.expected {
+				t.Errorf("Accept-Language should be %q, but got: %q", tc.expected, v)
+			}
+		})
+	}
+
+	t.Run("ManyTags", func(t *testing.T) {
+		tags := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
+		if v := formatAcceptLanguage(tags); v != "a, b;q=0.9, c;q=0.8, d;q=0.7, e;q=0.6, f;q=0.5, g;q=0.4, h;q=0.3, i;q=0.2, j;q=0.1, k;q=0.1, l;q=0.1" {
+			t.Errorf("Unexpected value: %s", v)
+		}
+	})
+
+	t.Run("NonIncreasing", func(t *testing.T) {
+		for _, tags := range [][]string{
+			{"ja", "en-US;q=0.5", "en", "fr", "de", "it", "es"},
+			{"ja;q=0.8", "en", "fr;q=0.15", "de", "it"},
+			{"ja", "en;q=0", "fr"},
+		} {
+			lowest := 1000
+			for _, value := range strings.Split(formatAcceptLanguage(tags), ", ") {
+				q, ok := tagQuality(value)
+				if !ok {
+					q = 1000
+				}
+				if q > lowest {
+					t.Errorf("Weights should never increase, but got: %s", formatAcceptLanguage(tags))
+					break
+				}
+				lowest = q
+			}
+		}
+	})
+
+	t.Run("NoLanguage", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&AcceptLanguageHook{}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := req.Header["Accept-Language"]; ok {
+			t.Errorf("Accept-Language should not be set, but got: %#v", req.Header)
+		}
+	})
+}
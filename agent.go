@@ -2,10 +2,23 @@ package httpagent
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	ErrNoClient = errors.New("httpagent: no client is configured")
+	ErrOffline  = errors.New("httpagent: offline")
+
+	ErrTooManyOpenBodies = errors.New("httpagent: too many open response bodies")
+)
+
 var DefaultAgent = NewAgent(http.DefaultClient)
 
 func NewAgent(client Client) *Agent {
@@ -15,68 +28,391 @@ func NewAgent(client Client) *Agent {
 		DefaultHeader: header,
 		RequestHooks:  NewRequestHooks(),
 		ResponseHooks: NewResponseHooks(),
+		HookRegistry:  NewHookRegistry(),
 	}
 }
 
 type Agent struct {
 	Client         Client
+	BaseURL        string
+	BaseContext    context.Context
 	DefaultTimeout time.Duration
 	DefaultHeader  http.Header
 	RequestHooks   *RequestHooks
 	ResponseHooks  *ResponseHooks
+	HookRegistry   *HookRegistry
+	RedirectPolicy *RedirectPolicy
+	MaxRetries     int
+	Backoff        func(attempt int) time.Duration
+	RetryStatuses  []int
+
+	// RetryNonIdempotent retries the network errors of non-idempotent requests without Idempotency-Key too.
+	RetryNonIdempotent bool
+
+	// MaxRetryAfter limits waiting for Retry-After of the response to retry. (0 means unlimited, but the context deadline still bounds it)
+	MaxRetryAfter time.Duration
+
+	FallbackClient Client
+	FallbackOn     func(*http.Response, error) bool
+	Clock          Clock
+
+	// MaxInFlight limits the concurrent requests until the response bodies are closed. (0 means unlimited)
+	MaxInFlight int
+
+	// MaxOpenBodies makes Do fail with ErrTooManyOpenBodies while this many response bodies are open. (0 means unlimited)
+	MaxOpenBodies int
+
+	mu         sync.RWMutex
+	inFlight   chan struct{}
+	openBodies int32
+}
+
+func (a *Agent) clock() Clock {
+	if a.Clock == nil {
+		return RealClock
+	}
+	return a.Clock
 }
 
 func nop() {}
 
 func (a *Agent) Do(req *http.Request) (*http.Response, error) {
-	// apply default headers
-	err := (&RequestHeaderHook{Header: a.DefaultHeader, SkipIfExists: true}).Do(req)
+	// substitute the base context for requests built without one
+	if a.BaseContext != nil && req.Context() == context.Background() {
+		req = req.WithContext(a.BaseContext)
+	}
+
+	// fail fast when too many response bodies are left open
+	if a.MaxOpenBodies > 0 && a.OpenBodies() >= a.MaxOpenBodies {
+		return nil, ErrTooManyOpenBodies
+	}
+
+	sem := a.inFlightSemaphore()
+	if sem == nil && a.MaxOpenBodies <= 0 {
+		return a.do(req)
+	}
+
+	// limit in-flight requests
+	release := nop
+	if sem != nil {
+		err := acquireSemaphore(req.Context(), sem)
+		if err != nil {
+			return nil, err
+		}
+		release = func() { <-sem }
+	}
+
+	res, err := a.do(req)
 	if err != nil {
+		release()
 		return nil, err
 	}
+	if res.Body == nil || res.Body == http.NoBody {
+		release()
+		return res, nil
+	}
 
-	// do request hooks
-	err = a.RequestHooks.Do(req)
+	atomic.AddInt32(&a.openBodies, 1)
+	res.Body = releaseOnClose(res.Body, func() {
+		atomic.AddInt32(&a.openBodies, -1)
+		release()
+	}, false)
+	return res, nil
+}
+
+// OpenBodies returns the number of the response bodies returned by Do and not closed yet.
+// It is counted only while MaxOpenBodies or MaxInFlight is set.
+func (a *Agent) OpenBodies() int {
+	return int(atomic.LoadInt32(&a.openBodies))
+}
+
+func (a *Agent) inFlightSemaphore() chan struct{} {
+	if a.MaxInFlight <= 0 {
+		return nil
+	}
+
+	a.mu.RLock()
+	sem := a.inFlight
+	a.mu.RUnlock()
+	if sem != nil && cap(sem) == a.MaxInFlight {
+		return sem
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.inFlight == nil || cap(a.inFlight) != a.MaxInFlight {
+		a.inFlight = make(chan struct{}, a.MaxInFlight)
+	}
+	return a.inFlight
+}
+
+// releaseOnClose wraps the body to call release once it is closed (or reaches EOF if releaseOnEOF).
+// The body of "101 Switching Protocols" keeps being writable.
+func releaseOnClose(body io.ReadCloser, release func(), releaseOnEOF bool) io.ReadCloser {
+	rc := &releaseReadCloser{ReadCloser: body, release: release, releaseOnEOF: releaseOnEOF}
+	if w, ok := body.(io.Writer); ok {
+		return &releaseReadWriteCloser{releaseReadCloser: rc, Writer: w}
+	}
+	return rc
+}
+
+type releaseReadWriteCloser struct {
+	*releaseReadCloser
+	io.Writer
+}
+
+type releaseReadCloser struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+
+	// releaseOnEOF releases also on reaching EOF
+	releaseOnEOF bool
+}
+
+func (r *releaseReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err == io.EOF && r.releaseOnEOF {
+		r.once.Do(r.release)
+	}
+	return n, err
+}
+
+func (r *releaseReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+func (a *Agent) do(req *http.Request) (*http.Response, error) {
+	// fail fast when offline
+	if contextOffline(req.Context()) {
+		return nil, ErrOffline
+	}
+
+	// resolve a relative URL
+	if a.BaseURL != "" && !req.URL.IsAbs() {
+		base, err := url.Parse(a.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		req.URL = base.ResolveReference(req.URL)
+	}
+
+	// apply request-scoped headers, and then default headers
+	err := (&RequestHeaderHook{Header: contextHeader(req.Context()), SkipIfExists: true}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	err = (&RequestHeaderHook{Header: a.DefaultHeader, SkipIfExists: true}).Do(req)
 	if err != nil {
 		return nil, err
 	}
 
+	// take a consistent snapshot of hooks
+	a.mu.RLock()
+	requestHooks, responseHooks := a.RequestHooks, a.ResponseHooks
+	a.mu.RUnlock()
+
+	// do request hooks
+	if requestHooks != nil {
+		err = requestHooks.Do(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if a.HookRegistry != nil {
+		err = a.HookRegistry.doRequest(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// get client
 	client := contextClient(req.Context())
 	if client == nil {
 		client = a.Client
 	}
+	if client == nil {
+		return nil, ErrNoClient
+	}
 
 	// apply timeout
+	timeout := a.timeout(req.Context())
 	cancel := nop
-	if a.DefaultTimeout > 0 {
+	if timeout > 0 {
 		var ctx context.Context
-		ctx, cancel = context.WithTimeout(req.Context(), a.DefaultTimeout)
+		ctx, cancel = withClockTimeout(req.Context(), a.clock(), timeout)
 		req = req.WithContext(ctx)
 	}
 
 	// do request
-	res, err := client.Do(req)
-	cancel()
+	res, err := a.sendWithRetry(client, req)
+	if a.FallbackClient != nil {
+		res, err = a.fallback(req, res, err)
+	}
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
+	// keep the timeout until the body is read
+	if res.Body == nil || res.Body == http.NoBody || timeout <= 0 {
+		cancel()
+	} else {
+		res.Body = releaseOnClose(res.Body, cancel, true)
+	}
+
 	// do response hooks
-	err = a.ResponseHooks.Do(res)
-	if err != nil {
-		return nil, err
+	if responseHooks != nil {
+		err = responseHooks.Do(res)
+		if err != nil {
+			DrainAndClose(res)
+			return nil, err
+		}
+	}
+	if a.HookRegistry != nil {
+		err = a.HookRegistry.doResponse(res)
+		if err != nil {
+			DrainAndClose(res)
+			return nil, err
+		}
 	}
 
 	return res, nil
 }
 
+// timeout returns the smaller of DefaultTimeout and the timeout of the context. (0 means no timeout)
+func (a *Agent) timeout(ctx context.Context) time.Duration {
+	timeout := a.DefaultTimeout
+	if d := contextTimeout(ctx); d > 0 && (timeout <= 0 || d < timeout) {
+		timeout = d
+	}
+	return timeout
+}
+
+func (a *Agent) send(client Client, req *http.Request) (*http.Response, error) {
+	res, err := client.Do(req)
+	if err == nil && a.RedirectPolicy != nil {
+		res, err = a.RedirectPolicy.follow(client, req, res)
+	}
+	return res, err
+}
+
+func (a *Agent) SetRequestHooks(hooks *RequestHooks) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.RequestHooks = hooks
+}
+
+func (a *Agent) SetResponseHooks(hooks *ResponseHooks) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ResponseHooks = hooks
+}
+
 func (a *Agent) WithClient(client Client) *Agent {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 	return &Agent{
-		Client:         client,
-		DefaultTimeout: a.DefaultTimeout,
-		DefaultHeader:  a.DefaultHeader.Clone(),
-		RequestHooks:   a.RequestHooks.Clone(),
-		ResponseHooks:  a.ResponseHooks.Clone(),
+		Client:             client,
+		BaseURL:            a.BaseURL,
+		BaseContext:        a.BaseContext,
+		DefaultTimeout:     a.DefaultTimeout,
+		DefaultHeader:      a.DefaultHeader.Clone(),
+		RequestHooks:       a.RequestHooks.Clone(),
+		ResponseHooks:      a.ResponseHooks.Clone(),
+		HookRegistry:       a.HookRegistry.Clone(),
+		RedirectPolicy:     a.RedirectPolicy,
+		MaxRetries:         a.MaxRetries,
+		Backoff:            a.Backoff,
+		RetryStatuses:      a.RetryStatuses,
+		MaxRetryAfter:      a.MaxRetryAfter,
+		RetryNonIdempotent: a.RetryNonIdempotent,
+		FallbackClient:     a.FallbackClient,
+		FallbackOn:         a.FallbackOn,
+		Clock:              a.Clock,
+		MaxInFlight:        a.MaxInFlight,
+		MaxOpenBodies:      a.MaxOpenBodies,
+	}
+}
+
+func (a *Agent) DoCopy(req *http.Request, w io.Writer) (int64, *http.Response, error) {
+	res, err := a.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	n, err := io.Copy(w, res.Body)
+	return n, res, err
+}
+
+// DoAndFetchCanonical sends the request, and fetches Content-Location of the response after a successful write (POST, PUT or PATCH).
+// Content-Location of another origin is not followed not to send the credentials there, and the original response is returned.
+func (a *Agent) DoAndFetchCanonical(req *http.Request) (*http.Response, error) {
+	res, err := a.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res, nil
+	}
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return res, nil
+	}
+
+	location := res.Header.Get("Content-Location")
+	if location == "" {
+		return res, nil
+	}
+	u, err := req.URL.Parse(location)
+	if err != nil {
+		DrainAndClose(res)
+		return nil, err
+	}
+	if u.Scheme != req.URL.Scheme || !strings.EqualFold(u.Host, req.URL.Host) {
+		return res, nil
+	}
+	DrainAndClose(res)
+
+	// follow only once
+	canonicalReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return a.Do(canonicalReq)
+}
+
+func (a *Agent) Get(ctx context.Context, url string) (*http.Response, error) {
+	return a.doMethod(ctx, http.MethodGet, url, "", nil)
+}
+
+func (a *Agent) Post(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	return a.doMethod(ctx, http.MethodPost, url, contentType, body)
+}
+
+func (a *Agent) Put(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	return a.doMethod(ctx, http.MethodPut, url, contentType, body)
+}
+
+func (a *Agent) Patch(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	return a.doMethod(ctx, http.MethodPatch, url, contentType, body)
+}
+
+func (a *Agent) Delete(ctx context.Context, url string) (*http.Response, error) {
+	return a.doMethod(ctx, http.MethodDelete, url, "", nil)
+}
+
+func (a *Agent) doMethod(ctx context.Context, method, url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
 	}
+	return a.Do(req)
 }
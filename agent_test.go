@@ -1,6 +1,7 @@
 package httpagent
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -11,6 +12,8 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -66,6 +69,45 @@ func TestAgentWithClient(t *testing.T) {
 	if agent2.ResponseHooks == agent1.ResponseHooks {
 		t.Errorf("agent.ResponseHooks should be changed, but got: %#v", agent2.ResponseHooks)
 	}
+	if agent2.HookRegistry == agent1.HookRegistry {
+		t.Errorf("agent.HookRegistry should be changed, but got: %#v", agent2.HookRegistry)
+	}
+
+	t.Run("MultiValueHeader", func(t *testing.T) {
+		agent1 := NewAgent(client1)
+		agent1.DefaultHeader.Add("Foo", "a")
+		agent1.DefaultHeader.Add("Foo", "b")
+		agent1.DefaultHeader.Add("Foo", "c")
+
+		agent2 := agent1.WithClient(client2)
+		expected := []string{"a", "b", "c"}
+		if values := agent2.DefaultHeader.Values("Foo"); !reflect.DeepEqual(values, expected) {
+			t.Errorf("Foo header should be %#v, but got: %#v", expected, values)
+		}
+		if len(agent2.DefaultHeader) != 1 {
+			t.Errorf("DefaultHeader should have only Foo, but got: %#v", agent2.DefaultHeader)
+		}
+
+		agent2.DefaultHeader.Set("Foo", "d")
+		if values := agent1.DefaultHeader.Values("Foo"); !reflect.DeepEqual(values, expected) {
+			t.Errorf("Original Foo header should be kept, but got: %#v", values)
+		}
+	})
+
+	t.Run("ZeroValue", func(t *testing.T) {
+		agent := (&Agent{}).WithClient(mockhttp.NewResponseMock(http.StatusOK, nil, nil).MakeClient())
+		if agent.RequestHooks != nil || agent.ResponseHooks != nil || agent.HookRegistry != nil {
+			t.Errorf("Hooks should be kept nil, but got: %#v", agent)
+		}
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode should be 200, but got: %d", res.StatusCode)
+		}
+	})
 }
 
 func TestAgentDo(t *testing.T) {
@@ -94,6 +136,57 @@ func TestAgentDo(t *testing.T) {
 		}
 	})
 
+	t.Run("NoClient", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		shouldBeError(t, &Agent{}, req, ErrNoClient)
+	})
+
+	t.Run("Offline", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		var called int
+		agent := NewAgent(http.DefaultClient)
+		agent.RequestHooks.Append(RequestHookFunc(func(req *http.Request) error {
+			called++
+			return nil
+		}))
+
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		shouldBeError(t, agent, req.WithContext(ContextWithOffline(req.Context())), ErrOffline)
+		if called != 0 {
+			t.Errorf("Request hook should not be called, but it called %d times", called)
+		}
+
+		shouldBeOK(t, agent, req, 1)
+	})
+
+	t.Run("BaseContext", func(t *testing.T) {
+		type keyType struct{}
+		ts := setupTestServer(t)
+
+		var got []interface{}
+		agent := NewAgent(http.DefaultClient)
+		agent.BaseContext = context.WithValue(context.Background(), keyType{}, "base")
+		agent.RequestHooks.Append(RequestHookFunc(func(req *http.Request) error {
+			got = append(got, req.Context().Value(keyType{}))
+			return nil
+		}))
+
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		shouldBeOK(t, agent, req, 1)
+		if req.Context() != context.Background() {
+			t.Error("Original request should not be modified")
+		}
+
+		ctx := context.WithValue(context.Background(), keyType{}, "request")
+		shouldBeOK(t, agent, req.WithContext(ctx), 2)
+
+		expected := []interface{}{"base", "request"}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Context values should be %#v, but got: %#v", expected, got)
+		}
+	})
+
 	t.Run("WithDefaultHeader", func(t *testing.T) {
 		ts := setupTestServer(t)
 
@@ -112,6 +205,33 @@ func TestAgentDo(t *testing.T) {
 		})
 	})
 
+	t.Run("WithContextHeader", func(t *testing.T) {
+		agent := NewAgent(http.DefaultClient)
+		agent.DefaultHeader.Set("Test-Increment", "100")
+
+		header := http.Header{}
+		header.Set("Test-Increment", "10")
+
+		t.Run("OverDefaultHeader", func(t *testing.T) {
+			ts := setupTestServer(t)
+			req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+			shouldBeOK(t, agent, req.WithContext(ContextWithHeader(req.Context(), header)), 11)
+		})
+
+		t.Run("NoOverwrite", func(t *testing.T) {
+			ts := setupTestServer(t)
+			req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+			req.Header.Set("Test-Increment", "1000")
+			shouldBeOK(t, agent, req.WithContext(ContextWithHeader(req.Context(), header)), 1001)
+		})
+
+		t.Run("NoContextHeader", func(t *testing.T) {
+			ts := setupTestServer(t)
+			req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+			shouldBeOK(t, agent, req, 101)
+		})
+	})
+
 	t.Run("WithDefaultTimeout", func(t *testing.T) {
 		agent := NewAgent(http.DefaultClient)
 		agent.DefaultTimeout = 3 * time.Second
@@ -131,6 +251,40 @@ func TestAgentDo(t *testing.T) {
 			shouldBeError(t, agent, req, &url.Error{Err: context.DeadlineExceeded})
 		})
 
+		t.Run("LargeBody", func(t *testing.T) {
+			const size = 4 << 20
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(w, io.LimitReader(zeroReader{}, size))
+			}))
+			t.Cleanup(ts.Close)
+
+			for _, c := range []struct {
+				name string
+				ctx  context.Context
+			}{
+				{name: "DefaultTimeout", ctx: context.Background()},
+				{name: "ContextTimeout", ctx: WithTimeout(context.Background(), time.Second)},
+			} {
+				c := c
+				t.Run(c.name, func(t *testing.T) {
+					req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+					res, err := agent.Do(req.WithContext(c.ctx))
+					if err != nil {
+						t.Fatalf("Unexpected error is occurred: %#v", err)
+					}
+					defer res.Body.Close()
+
+					n, err := io.Copy(io.Discard, res.Body)
+					if err != nil {
+						t.Fatalf("Unexpected error is occurred after %d bytes: %#v", n, err)
+					}
+					if n != size {
+						t.Errorf("Body should be %d bytes, but got: %d", size, n)
+					}
+				})
+			}
+		})
+
 		t.Run("NestedContext", func(t *testing.T) {
 			ts := setupTestServer(t)
 
@@ -150,6 +304,52 @@ func TestAgentDo(t *testing.T) {
 				t.Errorf("Unexpected timeout: %#v", d)
 			}
 		})
+
+		t.Run("ContextTimeout", func(t *testing.T) {
+			ts := setupTestServer(t)
+
+			req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+			req.Header.Set("Test-Sleep", "2")
+			req = req.WithContext(WithTimeout(req.Context(), 1*time.Second))
+
+			before := time.Now()
+			shouldBeError(t, agent, req, &url.Error{Err: context.DeadlineExceeded})
+			after := time.Now()
+
+			if d := after.Sub(before); d > time.Second*2 {
+				t.Errorf("Unexpected timeout: %#v", d)
+			}
+		})
+
+		t.Run("NestedContextTimeout", func(t *testing.T) {
+			ts := setupTestServer(t)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			// the earliest deadline wins
+			req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+			req.Header.Set("Test-Sleep", "2")
+			req = req.WithContext(WithTimeout(ctx, 5*time.Second))
+
+			before := time.Now()
+			shouldBeError(t, agent, req, &url.Error{Err: context.DeadlineExceeded})
+			after := time.Now()
+
+			if d := after.Sub(before); d > time.Second*2 {
+				t.Errorf("Unexpected timeout: %#v", d)
+			}
+		})
+	})
+
+	t.Run("WithContextTimeout", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		agent := NewAgent(http.DefaultClient)
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		req.Header.Set("Test-Sleep", "2")
+		req = req.WithContext(WithTimeout(req.Context(), 1*time.Second))
+		shouldBeError(t, agent, req, &url.Error{Err: context.DeadlineExceeded})
 	})
 
 	t.Run("RequestHook", func(t *testing.T) {
@@ -223,6 +423,219 @@ func TestAgentDo(t *testing.T) {
 	})
 }
 
+func TestAgentSetHooks(t *testing.T) {
+	var requestCalled, responseCalled int32
+	agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}))
+
+	newPipeline := func() (*RequestHooks, *ResponseHooks) {
+		requestHooks := NewRequestHooks(RequestHookFunc(func(req *http.Request) error {
+			atomic.AddInt32(&requestCalled, 1)
+			return nil
+		}))
+		responseHooks := NewResponseHooks(ResponseHookFunc(func(res *http.Response) error {
+			atomic.AddInt32(&responseCalled, 1)
+			return nil
+		}))
+		return requestHooks, responseHooks
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			requestHooks, responseHooks := newPipeline()
+			agent.SetRequestHooks(requestHooks)
+			agent.SetResponseHooks(responseHooks)
+		}()
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+			_, err := agent.Do(req)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	requestHooks, responseHooks := newPipeline()
+	agent.SetRequestHooks(requestHooks)
+	agent.SetResponseHooks(responseHooks)
+	if agent.RequestHooks != requestHooks || agent.ResponseHooks != responseHooks {
+		t.Errorf("Hooks should be replaced, but got: %#v, %#v", agent.RequestHooks, agent.ResponseHooks)
+	}
+
+	before := atomic.LoadInt32(&requestCalled)
+	req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+	_, err := agent.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requestCalled); n != before+1 {
+		t.Errorf("Replaced request hooks should be called at once, but it called %d times", n-before)
+	}
+}
+
+func TestAgentDoCopy(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		buf := &bytes.Buffer{}
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		n, res, err := DefaultAgent.DoCopy(req, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("Unexpected response: %#v", res)
+		}
+		if s := buf.String(); s != "OK: count=1" {
+			t.Errorf("Unexpected body: %s", s)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("Copied bytes should be %d, but got: %d", buf.Len(), n)
+		}
+	})
+
+	t.Run("CopyError", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		expectedErr := errors.New("oops")
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		_, res, err := DefaultAgent.DoCopy(req, writerFunc(func(p []byte) (int, error) {
+			return 0, expectedErr
+		}))
+		if err != expectedErr {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if res == nil || res.Header.Get("Foo") != "Bar" {
+			t.Errorf("Response should be returned for header inspection, but got: %#v", res)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		_, res, err := (&Agent{}).DoCopy(req, &bytes.Buffer{})
+		if err != ErrNoClient {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if res != nil {
+			t.Errorf("Should be no response, but got: %#v", res)
+		}
+	})
+}
+
+func TestAgentDoAndFetchCanonical(t *testing.T) {
+	var fetched int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/items":
+			w.Header().Set("Content-Location", "/items/1")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, "created")
+		case "/items/1":
+			atomic.AddInt32(&fetched, 1)
+			w.Header().Set("Content-Location", "/items/1")
+			fmt.Fprintf(w, "%s canonical", r.Method)
+		case "/failed":
+			w.Header().Set("Content-Location", "/items/1")
+			w.WriteHeader(http.StatusBadRequest)
+		case "/cross-origin":
+			w.Header().Set("Content-Location", "http://example.com/items/1")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, "created")
+		default:
+			fmt.Fprint(w, "plain")
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	readBody := func(t *testing.T, res *http.Response) string {
+		defer res.Body.Close()
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	t.Run("Follow", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, ts.URL+"/items", strings.NewReader("body"))
+		res, err := DefaultAgent.DoAndFetchCanonical(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := readBody(t, res); s != "GET canonical" {
+			t.Errorf("Canonical resource should be fetched, but got: %s", s)
+		}
+		if n := atomic.LoadInt32(&fetched); n != 1 {
+			t.Errorf("Canonical resource should be fetched at once, but fetched %d times", n)
+		}
+	})
+
+	t.Run("NoContentLocation", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, ts.URL+"/plain", nil)
+		res, err := DefaultAgent.DoAndFetchCanonical(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := readBody(t, res); s != "plain" {
+			t.Errorf("Original response should be returned, but got: %s", s)
+		}
+	})
+
+	t.Run("NotSuccess", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, ts.URL+"/failed", nil)
+		res, err := DefaultAgent.DoAndFetchCanonical(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("Original response should be returned, but got: %#v", res)
+		}
+	})
+
+	t.Run("NotWrite", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, ts.URL+"/items", nil)
+		res, err := DefaultAgent.DoAndFetchCanonical(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := readBody(t, res); s != "created" {
+			t.Errorf("Original response should be returned, but got: %s", s)
+		}
+	})
+
+	t.Run("CrossOrigin", func(t *testing.T) {
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "example.com" {
+				t.Errorf("Another origin should not be fetched: %s", req.URL)
+			}
+			return http.DefaultClient.Do(req)
+		}))
+		agent.DefaultHeader.Set("Authorization", "Bearer secret")
+
+		req := mustNewRequest(t, http.MethodPost, ts.URL+"/cross-origin", strings.NewReader("body"))
+		res, err := agent.DoAndFetchCanonical(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := readBody(t, res); s != "created" {
+			t.Errorf("Original response should be returned, but got: %s", s)
+		}
+	})
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
 func setupTestServer(t *testing.T) (ts *httptest.Server) {
 	var c int32
 	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -296,3 +709,267 @@ func shouldBeError(t *testing.T, agent *Agent, req *http.Request, expectedErr er
 		}
 	}
 }
+
+func TestAgentMethods(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s %s", r.Method, r.Header.Get("Content-Type"), r.Header.Get("Foo"), body)
+	}))
+	t.Cleanup(ts.Close)
+
+	var called int
+	agent := NewAgent(http.DefaultClient)
+	agent.DefaultHeader.Set("Foo", "Bar")
+	agent.RequestHooks.Append(RequestHookFunc(func(req *http.Request) error {
+		called++
+		return nil
+	}))
+
+	ctx := context.Background()
+	cases := map[string]struct {
+		do       func() (*http.Response, error)
+		expected string
+	}{
+		"Get": {
+			do: func() (*http.Response, error) {
+				return agent.Get(ctx, ts.URL)
+			},
+			expected: "GET  Bar ",
+		},
+		"Post": {
+			do: func() (*http.Response, error) {
+				return agent.Post(ctx, ts.URL, "text/plain", strings.NewReader("post"))
+			},
+			expected: "POST text/plain Bar post",
+		},
+		"Put": {
+			do: func() (*http.Response, error) {
+				return agent.Put(ctx, ts.URL, "text/plain", strings.NewReader("put"))
+			},
+			expected: "PUT text/plain Bar put",
+		},
+		"Patch": {
+			do: func() (*http.Response, error) {
+				return agent.Patch(ctx, ts.URL, "text/plain", strings.NewReader("patch"))
+			},
+			expected: "PATCH text/plain Bar patch",
+		},
+		"Delete": {
+			do: func() (*http.Response, error) {
+				return agent.Delete(ctx, ts.URL)
+			},
+			expected: "DELETE  Bar ",
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			before := called
+
+			res, err := c.do()
+			if err != nil {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			defer res.Body.Close()
+
+			b, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := string(b); s != c.expected {
+				t.Errorf("Body should be %q, but got: %q", c.expected, s)
+			}
+			if called != before+1 {
+				t.Errorf("Request hook should be called once, but called %d times", called-before)
+			}
+		})
+	}
+
+	t.Run("InvalidURL", func(t *testing.T) {
+		_, err := agent.Get(ctx, "://")
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+}
+
+func TestAgentMaxInFlight(t *testing.T) {
+	var fail int32
+	agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.LoadInt32(&fail) == 1 {
+			return nil, errors.New("oops")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("OK")), Request: req}, nil
+	}))
+	agent.MaxInFlight = 2
+
+	do := func(ctx context.Context) (*http.Response, error) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		return agent.Do(req.WithContext(ctx))
+	}
+
+	res1, err := do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res2, err := do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := do(ctx); err != context.Canceled {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		done := make(chan *http.Response, 1)
+		go func() {
+			res, err := do(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			done <- res
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("The 3rd request should be blocked until a body is closed")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		res1.Body.Close()
+		res1.Body.Close() // release only once
+		res3 := <-done
+		res3.Body.Close()
+	})
+
+	t.Run("ReleaseOnError", func(t *testing.T) {
+		atomic.StoreInt32(&fail, 1)
+		for i := 0; i < 3; i++ {
+			if _, err := do(context.Background()); err == nil {
+				t.Fatal("Should be error")
+			}
+		}
+		atomic.StoreInt32(&fail, 0)
+	})
+
+	res2.Body.Close()
+	if n := len(agent.inFlight); n != 0 {
+		t.Errorf("All slots should be released, but got %d in-flight requests", n)
+	}
+}
+
+func TestAgentOpenBodies(t *testing.T) {
+	agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("OK")), Request: req}, nil
+	}))
+	agent.MaxOpenBodies = 2
+
+	do := func() (*http.Response, error) {
+		return agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+	}
+
+	var responses []*http.Response
+	for i := 1; i <= 2; i++ {
+		res, err := do()
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		responses = append(responses, res)
+		if n := agent.OpenBodies(); n != i {
+			t.Errorf("OpenBodies should be %d, but got: %d", i, n)
+		}
+	}
+
+	if _, err := do(); err != ErrTooManyOpenBodies {
+		t.Errorf("Error should be ErrTooManyOpenBodies, but got: %#v", err)
+	}
+
+	responses[0].Body.Close()
+	responses[0].Body.Close() // count only once
+	if n := agent.OpenBodies(); n != 1 {
+		t.Errorf("OpenBodies should be 1, but got: %d", n)
+	}
+
+	res, err := do()
+	if err != nil {
+		t.Fatalf("Unexpected error is occurred: %#v", err)
+	}
+	res.Body.Close()
+	responses[1].Body.Close()
+	if n := agent.OpenBodies(); n != 0 {
+		t.Errorf("OpenBodies should be 0, but got: %d", n)
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func TestAgentBodyWrapping(t *testing.T) {
+	type readWriteCloser struct {
+		io.Reader
+		io.Writer
+		io.Closer
+	}
+
+	for _, c := range []struct {
+		name  string
+		setup func(*Agent)
+	}{
+		{name: "NoLimit", setup: func(*Agent) {}},
+		{name: "MaxInFlight", setup: func(a *Agent) { a.MaxInFlight = 1 }},
+		{name: "DefaultTimeout", setup: func(a *Agent) { a.DefaultTimeout = time.Second }},
+	} {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			var body io.ReadCloser
+			agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusSwitchingProtocols, Body: body, Request: req}, nil
+			}))
+			c.setup(agent)
+
+			t.Run("NoBody", func(t *testing.T) {
+				body = http.NoBody
+				res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+				if err != nil {
+					t.Fatalf("Unexpected error is occurred: %#v", err)
+				}
+				if res.Body != http.NoBody {
+					t.Errorf("Body should be http.NoBody, but got: %#v", res.Body)
+				}
+				if n := agent.OpenBodies(); n != 0 {
+					t.Errorf("OpenBodies should be 0, but got: %d", n)
+				}
+			})
+
+			t.Run("SwitchingProtocols", func(t *testing.T) {
+				var buf bytes.Buffer
+				body = readWriteCloser{Reader: strings.NewReader(""), Writer: &buf, Closer: io.NopCloser(nil)}
+				res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+				if err != nil {
+					t.Fatalf("Unexpected error is occurred: %#v", err)
+				}
+				defer res.Body.Close()
+
+				w, ok := res.Body.(io.Writer)
+				if !ok {
+					t.Fatalf("Body should be writable, but got: %#v", res.Body)
+				}
+				_, _ = io.WriteString(w, "ping")
+				if buf.String() != "ping" {
+					t.Errorf("Should be written to the connection, but got: %q", buf.String())
+				}
+			})
+		})
+	}
+}
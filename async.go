@@ -0,0 +1,99 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const DefaultPollInterval = time.Second
+
+// DoAsync sends the request, and polls the Location of "202 Accepted" responses until the operation completes.
+// The next poll waits for Retry-After of the interim response if present, or the interval (DefaultPollInterval if zero).
+// Polling is bounded by the context of the request, and DoAsync returns as soon as the context is done.
+func (a *Agent) DoAsync(req *http.Request, interval time.Duration) (*http.Response, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	type result struct {
+		res *http.Response
+		err error
+	}
+	ctx := req.Context()
+	ch := make(chan result, 1)
+	go func() {
+		res, err := a.poll(req, interval)
+		ch <- result{res: res, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		// discard the response arriving after giving up
+		go func() {
+			if r := <-ch; r.res != nil {
+				DrainAndClose(r.res)
+			}
+		}()
+		return nil, ctx.Err()
+	case r := <-ch:
+		return r.res, r.err
+	}
+}
+
+func (a *Agent) poll(req *http.Request, interval time.Duration) (*http.Response, error) {
+	res, err := a.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, current := req.Context(), req.URL
+	for res.StatusCode == http.StatusAccepted {
+		location := res.Header.Get("Location")
+		if location == "" {
+			return res, nil
+		}
+		u, err := current.Parse(location)
+		if err != nil {
+			DrainAndClose(res)
+			return nil, err
+		}
+
+		d, ok := ParseRetryAfter(res, a.clock().Now())
+		if !ok {
+			d = interval
+		}
+		DrainAndClose(res)
+
+		err = a.wait(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+
+		poll, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		current = poll.URL
+		res, err = a.Do(poll)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (a *Agent) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := a.clock().NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
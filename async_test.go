@@ -0,0 +1,156 @@
+package httpagent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+type recordingClock struct {
+	*fakeClock
+	mu        sync.Mutex
+	durations []time.Duration
+}
+
+func (c *recordingClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	c.durations = append(c.durations, d)
+	c.mu.Unlock()
+	return c.fakeClock.NewTimer(d)
+}
+
+func TestAgentDoAsync(t *testing.T) {
+	t.Run("RetryAfter", func(t *testing.T) {
+		clock := &recordingClock{fakeClock: newFakeClock()}
+
+		var urls []string
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			urls = append(urls, req.URL.String())
+
+			headers := map[string]string{"Location": "/status"}
+			switch len(urls) {
+			case 1:
+				headers["Retry-After"] = "3"
+			case 2:
+				headers["Retry-After"] = clock.Now().Add(5 * time.Second).Format(http.TimeFormat)
+			case 3:
+				headers["Retry-After"] = "invalid"
+			case 4:
+				// no Retry-After
+			default:
+				return mockhttp.NewResponseMock(http.StatusOK, nil, []byte("Done")).MakeResponse(req), nil
+			}
+			return mockhttp.NewResponseMock(http.StatusAccepted, headers, nil).MakeResponse(req), nil
+		}))
+		agent.Clock = clock
+
+		type result struct {
+			res *http.Response
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			res, err := agent.DoAsync(mustNewRequest(t, http.MethodPost, "http://example.com/jobs", nil), 7*time.Second)
+			done <- result{res: res, err: err}
+		}()
+
+		for i := 0; i < 4; i++ {
+			clock.WaitTimers(t, 1)
+			clock.Advance(time.Minute)
+		}
+
+		r := <-done
+		if r.err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", r.err)
+		}
+		b, err := io.ReadAll(r.res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != "Done" {
+			t.Errorf("Body should be Done, but got: %s", b)
+		}
+
+		expected := []time.Duration{3 * time.Second, 5 * time.Second, 7 * time.Second, 7 * time.Second}
+		if diff := cmp.Diff(expected, clock.durations); diff != "" {
+			t.Errorf("Unexpected poll delays: %s", diff)
+		}
+		expectedURLs := []string{
+			"http://example.com/jobs",
+			"http://example.com/status",
+			"http://example.com/status",
+			"http://example.com/status",
+			"http://example.com/status",
+		}
+		if diff := cmp.Diff(expectedURLs, urls); diff != "" {
+			t.Errorf("Unexpected URLs: %s", diff)
+		}
+	})
+
+	t.Run("NoLocation", func(t *testing.T) {
+		agent := NewAgent(mockhttp.NewResponseMock(http.StatusAccepted, nil, nil).MakeClient())
+
+		res, err := agent.DoAsync(mustNewRequest(t, http.MethodPost, "http://example.com/jobs", nil), 0)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if res.StatusCode != http.StatusAccepted {
+			t.Errorf("StatusCode should be 202, but got: %d", res.StatusCode)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		agent := NewAgent(mockhttp.NewResponseMock(http.StatusAccepted, map[string]string{
+			"Location": "/status",
+		}, nil).MakeClient())
+		agent.Clock = newFakeClock()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/jobs", nil)
+		_, err := agent.DoAsync(req.WithContext(ctx), 0)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Error should be context.Canceled, but got: %#v", err)
+		}
+	})
+}
+
+func TestAgentDoAsyncCancelInFlight(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	// the client ignores the context
+	started := make(chan struct{})
+	agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+		close(started)
+		<-block
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/jobs", nil)
+		_, err := agent.DoAsync(req.WithContext(ctx), 0)
+		done <- err
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Error should be context.Canceled, but got: %#v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("DoAsync should return once the context is done")
+	}
+}
@@ -0,0 +1,20 @@
+package httpagent
+
+import "net/http"
+
+type BasicAuthHook struct {
+	Username     string
+	Password     string
+	SkipIfExists bool
+}
+
+func (h *BasicAuthHook) Do(req *http.Request) error {
+	if h.SkipIfExists {
+		if _, ok := req.Header["Authorization"]; ok {
+			return nil
+		}
+	}
+
+	req.SetBasicAuth(h.Username, h.Password)
+	return nil
+}
@@ -0,0 +1,47 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBasicAuthHook(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&BasicAuthHook{Username: "Aladdin", Password: "open sesame"}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// RFC 7617 example
+		if auth := req.Header.Get("Authorization"); auth != "Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ==" {
+			t.Errorf("Authorization header should be Basic QWxhZGRpbjpvcGVuIHNlc2FtZQ==, but got: %s", auth)
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		err := (&BasicAuthHook{Username: "user", Password: "pass"}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		username, password, ok := req.BasicAuth()
+		if !ok || username != "user" || password != "pass" {
+			t.Errorf("Basic auth should be user:pass, but got: %s", req.Header.Get("Authorization"))
+		}
+	})
+
+	t.Run("SkipIfExists", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		err := (&BasicAuthHook{Username: "user", Password: "pass", SkipIfExists: true}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth := req.Header.Get("Authorization"); auth != "Bearer token" {
+			t.Errorf("Authorization header should be kept, but got: %s", auth)
+		}
+	})
+}
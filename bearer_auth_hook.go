@@ -0,0 +1,34 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+)
+
+type BearerAuthHook struct {
+	Token string
+
+	// TokenSource is used instead of Token if set, to refresh the token per request.
+	TokenSource  func(context.Context) (string, error)
+	SkipIfExists bool
+}
+
+func (h *BearerAuthHook) Do(req *http.Request) error {
+	if h.SkipIfExists {
+		if _, ok := req.Header["Authorization"]; ok {
+			return nil
+		}
+	}
+
+	token := h.Token
+	if h.TokenSource != nil {
+		var err error
+		token, err = h.TokenSource(req.Context())
+		if err != nil {
+			return err
+		}
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
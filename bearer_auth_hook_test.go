@@ -0,0 +1,72 @@
+package httpagent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestBearerAuthHook(t *testing.T) {
+	t.Run("Token", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer old")
+		err := (&BearerAuthHook{Token: "secret"}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values := req.Header.Values("Authorization"); len(values) != 1 || values[0] != "Bearer secret" {
+			t.Errorf("Authorization header should be Bearer secret, but got: %#v", values)
+		}
+	})
+
+	t.Run("TokenSource", func(t *testing.T) {
+		var n int
+		hook := &BearerAuthHook{Token: "unused", TokenSource: func(ctx context.Context) (string, error) {
+			n++
+			if n == 1 {
+				return "first", nil
+			}
+			return "second", nil
+		}}
+
+		for _, expected := range []string{"Bearer first", "Bearer second"} {
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+			err := hook.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if auth := req.Header.Get("Authorization"); auth != expected {
+				t.Errorf("Authorization header should be %s, but got: %s", expected, auth)
+			}
+		}
+	})
+
+	t.Run("TokenSourceError", func(t *testing.T) {
+		expectedErr := errors.New("token expired")
+		hook := &BearerAuthHook{TokenSource: func(ctx context.Context) (string, error) {
+			return "", expectedErr
+		}}
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := hook.Do(req)
+		if err != expectedErr {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if auth := req.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Authorization header should not be set, but got: %s", auth)
+		}
+	})
+
+	t.Run("SkipIfExists", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer mine")
+		err := (&BearerAuthHook{Token: "secret", SkipIfExists: true}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if auth := req.Header.Get("Authorization"); auth != "Bearer mine" {
+			t.Errorf("Authorization header should be kept, but got: %s", auth)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package httpagent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+// Read delivers the data decompressed before the stream ends unexpectedly, and then returns ErrTruncatedBody.
+func (r *gzipReadCloser) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.ErrUnexpectedEOF || err == gzip.ErrChecksum {
+		if n > 0 {
+			// the gzip reader returns the same error again on the next read
+			return n, nil
+		}
+		return 0, fmt.Errorf("%w: %s", ErrTruncatedBody, err)
+	}
+	return n, err
+}
+
+func (r *gzipReadCloser) Close() error {
+	_ = r.Reader.Close()
+	return r.body.Close()
+}
+
+type decoderReadCloser struct {
+	io.ReadCloser
+	body io.Closer
+}
+
+func (r *decoderReadCloser) Close() error {
+	_ = r.ReadCloser.Close()
+	return r.body.Close()
+}
+
+// BufferRequestBody reads the request body into memory and installs GetBody to make it replayable.
+func BufferRequestBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	b, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+	// keep Content-Length consistent with the buffered body
+	req.ContentLength = int64(len(b))
+	if req.Header.Get("Content-Length") != "" {
+		req.Header.Set("Content-Length", strconv.Itoa(len(b)))
+	}
+	return nil
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}
+	return b, nil
+}
+
+func readResponseBody(res *http.Response) ([]byte, error) {
+	if res.Body == nil || res.Body == http.NoBody {
+		return nil, nil
+	}
+
+	b, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
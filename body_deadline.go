@@ -0,0 +1,106 @@
+package httpagent
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrBodyDeadlineExceeded = errors.New("httpagent: deadline to read the body exceeded")
+
+// WithBodyDeadline wraps the client to require reading the whole response body in d after the response is returned.
+// The body is closed on the deadline to interrupt the blocking read, and the read fails with ErrBodyDeadlineExceeded.
+func WithBodyDeadline(c Client, d time.Duration) Client {
+	return &BodyDeadlineClient{Client: c, Deadline: d}
+}
+
+// BodyDeadlineClient requires reading the whole response body in Deadline after the response is returned. (0 means no deadline)
+type BodyDeadlineClient struct {
+	Client   Client
+	Deadline time.Duration
+	Clock    Clock
+}
+
+var _ Client = &BodyDeadlineClient{}
+
+func (c *BodyDeadlineClient) clock() Clock {
+	if c.Clock == nil {
+		return RealClock
+	}
+	return c.Clock
+}
+
+func (c *BodyDeadlineClient) Do(req *http.Request) (*http.Response, error) {
+	res, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.Body == nil || res.Body == http.NoBody || c.Deadline <= 0 {
+		return res, nil
+	}
+
+	body := &deadlineReadCloser{ReadCloser: res.Body, timer: c.clock().NewTimer(c.Deadline), done: make(chan struct{})}
+	go body.wait()
+	res.Body = body
+	return res, nil
+}
+
+type deadlineReadCloser struct {
+	io.ReadCloser
+	timer     Timer
+	done      chan struct{}
+	stopOnce  sync.Once
+	expired   int32
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (r *deadlineReadCloser) wait() {
+	select {
+	case <-r.timer.C():
+		r.expire()
+	case <-r.done:
+	}
+}
+
+func (r *deadlineReadCloser) stop() {
+	r.stopOnce.Do(func() {
+		r.timer.Stop()
+		close(r.done)
+	})
+}
+
+func (r *deadlineReadCloser) expire() {
+	atomic.StoreInt32(&r.expired, 1)
+	_ = r.close()
+}
+
+func (r *deadlineReadCloser) Read(p []byte) (int, error) {
+	if atomic.LoadInt32(&r.expired) == 1 {
+		return 0, ErrBodyDeadlineExceeded
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if err == io.EOF {
+		r.stop()
+	} else if err != nil && atomic.LoadInt32(&r.expired) == 1 {
+		// the read is interrupted by closing the body
+		err = ErrBodyDeadlineExceeded
+	}
+	return n, err
+}
+
+func (r *deadlineReadCloser) Close() error {
+	r.stop()
+	return r.close()
+}
+
+func (r *deadlineReadCloser) close() error {
+	r.closeOnce.Do(func() {
+		r.closeErr = r.ReadCloser.Close()
+	})
+	return r.closeErr
+}
@@ -0,0 +1,92 @@
+package httpagent
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithBodyDeadline(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		clock := newFakeClock()
+		agent := NewAgent(&BodyDeadlineClient{
+			Client: ClientFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("OK")), Request: req}, nil
+			}),
+			Deadline: time.Second,
+			Clock:    clock,
+		})
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		defer res.Body.Close()
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if s := string(b); s != "OK" {
+			t.Errorf("Body should be OK, but got: %s", s)
+		}
+		if n := clock.ActiveTimers(); n != 0 {
+			t.Errorf("Timer should be stopped on EOF, but got %d active timers", n)
+		}
+	})
+
+	t.Run("Trickle", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		defer pw.Close()
+
+		clock := newFakeClock()
+		agent := NewAgent(&BodyDeadlineClient{
+			Client: ClientFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: pr, Request: req}, nil
+			}),
+			Deadline: time.Second,
+			Clock:    clock,
+		})
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		defer res.Body.Close()
+
+		go func() {
+			_, _ = io.WriteString(pw, ".")
+			clock.WaitTimers(t, 1)
+			clock.Advance(time.Second)
+		}()
+
+		b, err := io.ReadAll(res.Body)
+		if err != ErrBodyDeadlineExceeded {
+			t.Errorf("Error should be ErrBodyDeadlineExceeded, but got: %#v", err)
+		}
+		if s := string(b); s != "." {
+			t.Errorf("Body should be read partially, but got: %q", s)
+		}
+
+		if _, err := res.Body.Read(make([]byte, 1)); err != ErrBodyDeadlineExceeded {
+			t.Errorf("Error should be ErrBodyDeadlineExceeded after the deadline, but got: %#v", err)
+		}
+	})
+
+	t.Run("NoDeadline", func(t *testing.T) {
+		body := io.NopCloser(strings.NewReader("OK"))
+		client := WithBodyDeadline(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: body, Request: req}, nil
+		}), 0)
+
+		res, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if res.Body != body {
+			t.Errorf("Body should not be wrapped without deadline, but got: %#v", res.Body)
+		}
+	})
+}
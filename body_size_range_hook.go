@@ -0,0 +1,84 @@
+package httpagent
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type BodySizeError struct {
+	Size    int64
+	MinSize int64
+	MaxSize int64
+}
+
+func (e *BodySizeError) Error() string {
+	return fmt.Sprintf("httpagent: response body size %d is out of range [%d, %d]", e.Size, e.MinSize, e.MaxSize)
+}
+
+// BodySizeRangeHook bounds the response body size. Zero MinSize or MaxSize means no bound.
+// The error is returned by Do if Content-Length is out of range, otherwise by Read at EOF or Close.
+type BodySizeRangeHook struct {
+	MinSize int64
+	MaxSize int64
+}
+
+func (h *BodySizeRangeHook) Do(res *http.Response) error {
+	if res.ContentLength >= 0 && !h.inRange(res.ContentLength, true) {
+		DrainAndClose(res)
+		return h.newError(res.ContentLength)
+	}
+	if res.Body == nil {
+		return nil
+	}
+
+	res.Body = &bodySizeRangeReadCloser{ReadCloser: res.Body, hook: h}
+	return nil
+}
+
+func (h *BodySizeRangeHook) inRange(size int64, complete bool) bool {
+	if h.MaxSize > 0 && size > h.MaxSize {
+		return false
+	}
+	if complete && h.MinSize > 0 && size < h.MinSize {
+		return false
+	}
+	return true
+}
+
+func (h *BodySizeRangeHook) newError(size int64) error {
+	return &BodySizeError{Size: size, MinSize: h.MinSize, MaxSize: h.MaxSize}
+}
+
+type bodySizeRangeReadCloser struct {
+	io.ReadCloser
+	hook *BodySizeRangeHook
+	n    int64
+	eof  bool
+}
+
+func (r *bodySizeRangeReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	if !r.hook.inRange(r.n, false) {
+		return n, r.hook.newError(r.n)
+	}
+	if err == io.EOF {
+		r.eof = true
+		if !r.hook.inRange(r.n, true) {
+			return n, r.hook.newError(r.n)
+		}
+	}
+	return n, err
+}
+
+func (r *bodySizeRangeReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	if r.eof && !r.hook.inRange(r.n, true) {
+		return r.hook.newError(r.n)
+	}
+	if !r.hook.inRange(r.n, false) {
+		return r.hook.newError(r.n)
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package httpagent
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestBodySizeRangeHook(t *testing.T) {
+	hook := &BodySizeRangeHook{MinSize: 3, MaxSize: 5}
+
+	newResponse := func(t *testing.T, body string, unknownLength bool) *http.Response {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		res := mockhttp.NewResponseMock(http.StatusOK, nil, []byte(body)).MakeResponse(req)
+		if unknownLength {
+			res.ContentLength = -1
+			res.Body = ioutil.NopCloser(io.MultiReader(strings.NewReader(body)))
+		}
+		return res
+	}
+
+	shouldBeSizeError := func(t *testing.T, err error, size int64) {
+		t.Helper()
+		var sizeErr *BodySizeError
+		if !errors.As(err, &sizeErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if sizeErr.Size != size {
+			t.Errorf("Size should be %d, but got: %d", size, sizeErr.Size)
+		}
+	}
+
+	t.Run("InRange", func(t *testing.T) {
+		for _, unknown := range []bool{false, true} {
+			res := newResponse(t, "1234", unknown)
+			err := hook.Do(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := string(b); s != "1234" {
+				t.Errorf("Unexpected body: %q", s)
+			}
+			if err := res.Body.Close(); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	t.Run("UnderMin", func(t *testing.T) {
+		err := hook.Do(newResponse(t, "12", false))
+		shouldBeSizeError(t, err, 2)
+
+		res := newResponse(t, "12", true)
+		err = hook.Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ioutil.ReadAll(res.Body)
+		shouldBeSizeError(t, err, 2)
+		shouldBeSizeError(t, res.Body.Close(), 2)
+	})
+
+	t.Run("OverMax", func(t *testing.T) {
+		err := hook.Do(newResponse(t, "123456", false))
+		shouldBeSizeError(t, err, 6)
+
+		res := newResponse(t, "123456", true)
+		err = hook.Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ioutil.ReadAll(res.Body)
+		shouldBeSizeError(t, err, 6)
+	})
+
+	t.Run("NoBound", func(t *testing.T) {
+		res := newResponse(t, "", true)
+		err := (&BodySizeRangeHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+}
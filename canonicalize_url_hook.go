@@ -0,0 +1,126 @@
+package httpagent
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type CanonicalizeURLHook struct {
+	SortQuery bool
+}
+
+func (h *CanonicalizeURLHook) Do(req *http.Request) error {
+	u := req.URL
+	if u.Opaque != "" {
+		return nil
+	}
+
+	escapedPath, err := canonicalizeEscapes(u.EscapedPath())
+	if err != nil {
+		return err
+	}
+	escapedPath = cleanEscapedPath(escapedPath)
+	path, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		return err
+	}
+
+	rawQuery := u.RawQuery
+	if h.SortQuery {
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return err
+		}
+		rawQuery = query.Encode()
+	} else {
+		rawQuery, err = canonicalizeEscapes(rawQuery)
+		if err != nil {
+			return err
+		}
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Path = path
+	u.RawPath = escapedPath
+	u.RawQuery = rawQuery
+	return nil
+}
+
+// canonicalizeEscapes decodes escaped unreserved characters and uppercases the other escapes.
+func canonicalizeEscapes(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return "", fmt.Errorf("httpagent: invalid percent-encoding in %q", s)
+		}
+
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// cleanEscapedPath removes empty and dot segments keeping the trailing slash.
+func cleanEscapedPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	segments := strings.Split(p, "/")
+	cleaned := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(cleaned) != 0 {
+				cleaned = cleaned[:len(cleaned)-1]
+			}
+		default:
+			cleaned = append(cleaned, segment)
+		}
+	}
+
+	last := segments[len(segments)-1]
+	cleanedPath := "/" + strings.Join(cleaned, "/")
+	if len(cleaned) != 0 && (last == "" || last == "." || last == "..") {
+		cleanedPath += "/"
+	}
+	return cleanedPath
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,46 @@
+package httpagent
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestCanonicalizeURLHook(t *testing.T) {
+	cases := []struct {
+		name      string
+		sortQuery bool
+		input     string
+		expected  string
+	}{
+		{name: "MixedCaseEscapes", input: "http://example.com/a%2fb/%7euser/%e3%81%82", expected: "http://example.com/a%2Fb/~user/%E3%81%82"},
+		{name: "DoubleSlash", input: "http://example.com//foo//bar", expected: "http://example.com/foo/bar"},
+		{name: "DotSegments", input: "http://example.com/a/./b/../c/", expected: "http://example.com/a/c/"},
+		{name: "Root", input: "http://EXAMPLE.com", expected: "http://example.com/"},
+		{name: "QueryUntouched", input: "http://example.com/?b=%7e&a=%2f", expected: "http://example.com/?b=~&a=%2F"},
+		{name: "QuerySorted", sortQuery: true, input: "http://example.com/?b=2&a=1&a=0", expected: "http://example.com/?a=1&a=0&b=2"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := mustNewRequest(t, http.MethodGet, tc.input, nil)
+			err := (&CanonicalizeURLHook{SortQuery: tc.sortQuery}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := req.URL.String(); s != tc.expected {
+				t.Errorf("URL should be %s, but got: %s", tc.expected, s)
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.URL = &url.URL{Scheme: "http", Host: "example.com", Path: "/", RawQuery: "a=%zz"}
+		err := (&CanonicalizeURLHook{}).Do(req)
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+}
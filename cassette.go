@@ -0,0 +1,168 @@
+package httpagent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+var ErrCassetteNoMatch = errors.New("httpagent: no matching interaction in cassette")
+
+type CassetteMode int
+
+const (
+	CassetteReplay CassetteMode = iota
+	CassetteRecord
+)
+
+type CassetteRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type CassetteMatcher func(req *http.Request, recorded *CassetteRequest) bool
+
+func MatchMethodAndURL(req *http.Request, recorded *CassetteRequest) bool {
+	return req.Method == recorded.Method && req.URL.String() == recorded.URL
+}
+
+type CassetteClient struct {
+	Client  Client
+	Path    string
+	Mode    CassetteMode
+	Matcher CassetteMatcher
+
+	// RedactHeaders masks the values of the headers in the cassette. (nil means DefaultRedactHeaders, and empty means nothing)
+	RedactHeaders []string
+
+	mu           sync.Mutex
+	loaded       bool
+	interactions []*CassetteInteraction
+	replayed     map[*CassetteInteraction]bool
+}
+
+var _ Client = &CassetteClient{}
+
+func (c *CassetteClient) Do(req *http.Request) (*http.Response, error) {
+	if c.Mode == CassetteRecord {
+		return c.record(req)
+	}
+	return c.replay(req)
+}
+
+func (c *CassetteClient) record(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := readResponseBody(res)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, &CassetteInteraction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redactHeader(req.Header, redactHeaders(c.RedactHeaders)),
+			Body:   reqBody,
+		},
+		Response: CassetteResponse{
+			StatusCode: res.StatusCode,
+			Header:     redactHeader(res.Header, redactHeaders(c.RedactHeaders)),
+			Body:       resBody,
+		},
+	})
+
+	b, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// the cassette may have secrets in the bodies
+	err = os.WriteFile(c.Path, b, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *CassetteClient) replay(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loaded {
+		b, err := os.ReadFile(c.Path)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(b, &c.interactions)
+		if err != nil {
+			return nil, err
+		}
+		c.replayed = map[*CassetteInteraction]bool{}
+		c.loaded = true
+	}
+
+	matcher := c.Matcher
+	if matcher == nil {
+		matcher = MatchMethodAndURL
+	}
+
+	// prefer interactions not yet replayed to keep recorded order
+	var found *CassetteInteraction
+	for _, interaction := range c.interactions {
+		if !matcher(req, &interaction.Request) {
+			continue
+		}
+		if found == nil {
+			found = interaction
+		}
+		if !c.replayed[interaction] {
+			found = interaction
+			break
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s %s", ErrCassetteNoMatch, req.Method, req.URL)
+	}
+	c.replayed[found] = true
+
+	return &http.Response{
+		Status:        strconv.Itoa(found.Response.StatusCode) + " " + http.StatusText(found.Response.StatusCode),
+		StatusCode:    found.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        found.Response.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(found.Response.Body)),
+		ContentLength: int64(len(found.Response.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package httpagent
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestCassetteClient(t *testing.T) {
+	ts := setupTestServer(t)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder := &CassetteClient{Client: http.DefaultClient, Path: path, Mode: CassetteRecord}
+	agent := NewAgent(recorder)
+	shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL+"/a", nil), 1)
+	shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL+"/a", nil), 2)
+	shouldBeOK(t, agent, mustNewRequest(t, http.MethodPost, ts.URL+"/b", strings.NewReader("body")), 3)
+
+	// replay without network
+	ts.Close()
+
+	player := &CassetteClient{Path: path}
+	agent = NewAgent(player)
+	t.Run("Replay", func(t *testing.T) {
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodPost, ts.URL+"/b", nil), 3)
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL+"/a", nil), 1)
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL+"/a", nil), 2)
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL+"/a", nil), 1)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		_, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL+"/c", nil))
+		if !errors.Is(err, ErrCassetteNoMatch) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("CustomMatcher", func(t *testing.T) {
+		player := &CassetteClient{
+			Path: path,
+			Matcher: func(req *http.Request, recorded *CassetteRequest) bool {
+				return req.Method == recorded.Method
+			},
+		}
+
+		res, err := player.Do(mustNewRequest(t, http.MethodPost, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "OK: count=3" {
+			t.Errorf("Unexpected body: %s", s)
+		}
+	})
+
+	t.Run("RecordedRequestBody", func(t *testing.T) {
+		if len(player.interactions) != 3 {
+			t.Fatalf("Should have 3 interactions, but got: %d", len(player.interactions))
+		}
+		if body := string(player.interactions[2].Request.Body); body != "body" {
+			t.Errorf("Request body should be recorded, but got: %q", body)
+		}
+	})
+	t.Run("File", func(t *testing.T) {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("Cassette should be written with 0600, but got: %o", perm)
+		}
+	})
+}
+
+func TestCassetteClientRedactHeaders(t *testing.T) {
+	client := ClientFunc(func(req *http.Request) (*http.Response, error) {
+		return mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+			"Set-Cookie": "session=secret",
+			"X-Api-Key":  "secret",
+		}, []byte("OK")).MakeResponse(req), nil
+	})
+
+	cases := []struct {
+		name       string
+		redact     []string
+		authorized string
+		apiKey     string
+	}{
+		{name: "Default", redact: nil, authorized: "REDACTED", apiKey: "secret"},
+		{name: "Custom", redact: []string{"X-Api-Key"}, authorized: "Bearer secret", apiKey: "REDACTED"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			recorder := &CassetteClient{Client: client, Path: filepath.Join(t.TempDir(), "cassette.json"), Mode: CassetteRecord, RedactHeaders: c.redact}
+
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			res, err := recorder.Do(req)
+			if err != nil {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			res.Body.Close()
+
+			interaction := recorder.interactions[0]
+			if v := interaction.Request.Header.Get("Authorization"); v != c.authorized {
+				t.Errorf("Authorization should be %q, but got: %q", c.authorized, v)
+			}
+			if v := interaction.Response.Header.Get("X-Api-Key"); v != c.apiKey {
+				t.Errorf("X-Api-Key should be %q, but got: %q", c.apiKey, v)
+			}
+			if req.Header.Get("Authorization") != "Bearer secret" || res.Header.Get("X-Api-Key") != "secret" {
+				t.Errorf("The real headers should not be modified: %#v %#v", req.Header, res.Header)
+			}
+		})
+	}
+}
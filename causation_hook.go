@@ -0,0 +1,72 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	DefaultCorrelationIDHeader = "X-Correlation-ID"
+	DefaultCausationIDHeader   = "X-Causation-ID"
+)
+
+type correlationIDContextKeyType struct{}
+
+var correlationIDContextKey = correlationIDContextKeyType{}
+
+type causationIDContextKeyType struct{}
+
+var causationIDContextKey = causationIDContextKeyType{}
+
+// ContextWithCorrelationID sets the correlation ID which is constant across a flow.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey, id)
+}
+
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDContextKey).(string)
+	return id
+}
+
+func CausationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(causationIDContextKey).(string)
+	return id
+}
+
+// CausationHook sets the correlation ID from the context (or generates it if absent) and a fresh causation ID for each request.
+// Both IDs are stored into the request context.
+type CausationHook struct {
+	CorrelationIDHeader string
+	CausationIDHeader   string
+}
+
+func (h *CausationHook) Do(req *http.Request) error {
+	correlationIDHeader := h.CorrelationIDHeader
+	if correlationIDHeader == "" {
+		correlationIDHeader = DefaultCorrelationIDHeader
+	}
+	causationIDHeader := h.CausationIDHeader
+	if causationIDHeader == "" {
+		causationIDHeader = DefaultCausationIDHeader
+	}
+
+	correlationID := CorrelationIDFromContext(req.Context())
+	if correlationID == "" {
+		id, err := newNonce()
+		if err != nil {
+			return err
+		}
+		correlationID = id
+		setRequestContextValue(req, correlationIDContextKey, correlationID)
+	}
+
+	causationID, err := newNonce()
+	if err != nil {
+		return err
+	}
+	setRequestContextValue(req, causationIDContextKey, causationID)
+
+	req.Header.Set(correlationIDHeader, correlationID)
+	req.Header.Set(causationIDHeader, causationID)
+	return nil
+}
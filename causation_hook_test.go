@@ -0,0 +1,56 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCausationHook(t *testing.T) {
+	t.Run("Flow", func(t *testing.T) {
+		var got []*http.Request
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			got = append(got, req)
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}))
+		agent.RequestHooks.Append(&CausationHook{})
+
+		ctx := ContextWithCorrelationID(context.Background(), "flow-1")
+		for i := 0; i < 2; i++ {
+			res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil).WithContext(ctx))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id := CausationIDFromContext(res.Request.Context()); id != res.Request.Header.Get(DefaultCausationIDHeader) {
+				t.Errorf("Causation ID should be stored in context, but got: %s", id)
+			}
+		}
+
+		for _, req := range got {
+			if id := req.Header.Get(DefaultCorrelationIDHeader); id != "flow-1" {
+				t.Errorf("Correlation ID should be flow-1, but got: %s", id)
+			}
+		}
+		first, second := got[0].Header.Get(DefaultCausationIDHeader), got[1].Header.Get(DefaultCausationIDHeader)
+		if first == "" || first == second {
+			t.Errorf("Causation IDs should be different, but got: %q and %q", first, second)
+		}
+	})
+
+	t.Run("Generate", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&CausationHook{CorrelationIDHeader: "X-Flow", CausationIDHeader: "X-Cause"}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		correlationID := CorrelationIDFromContext(req.Context())
+		if correlationID == "" || req.Header.Get("X-Flow") != correlationID {
+			t.Errorf("Correlation ID should be generated and stored, but got: %q (header: %q)", correlationID, req.Header.Get("X-Flow"))
+		}
+		causationID := CausationIDFromContext(req.Context())
+		if causationID == "" || req.Header.Get("X-Cause") != causationID {
+			t.Errorf("Causation ID should be generated and stored, but got: %q (header: %q)", causationID, req.Header.Get("X-Cause"))
+		}
+	})
+}
@@ -2,7 +2,9 @@ package httpagent
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"time"
 )
 
 type Client interface {
@@ -37,3 +39,58 @@ func contextClient(ctx context.Context) Client {
 	}
 	return client
 }
+
+type offlineContextKeyType struct{}
+
+var offlineContextKey = offlineContextKeyType{}
+
+func ContextWithOffline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, offlineContextKey, true)
+}
+
+func contextOffline(ctx context.Context) bool {
+	offline, _ := ctx.Value(offlineContextKey).(bool)
+	return offline
+}
+
+type timeoutContextKeyType struct{}
+
+var timeoutContextKey = timeoutContextKeyType{}
+
+// WithTimeout sets the timeout of the request. Agent.Do applies the smaller of it and Agent.DefaultTimeout.
+// It never extends the deadline of the context, so an earlier deadline of the parent context wins.
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutContextKey, d)
+}
+
+func contextTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(timeoutContextKey).(time.Duration)
+	return timeout
+}
+
+type headerContextKeyType struct{}
+
+var headerContextKey = headerContextKeyType{}
+
+// ContextWithHeader sets the headers to add to the request by Agent.Do.
+// The headers set to the request explicitly take precedence over them, and they take precedence over Agent.DefaultHeader.
+func ContextWithHeader(ctx context.Context, header http.Header) context.Context {
+	return context.WithValue(ctx, headerContextKey, header)
+}
+
+func contextHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headerContextKey).(http.Header)
+	return header
+}
+
+// maxDrainBytes limits reading the rest of the body to reuse the connection, like net/http does on redirects.
+const maxDrainBytes = 256 << 10
+
+// DrainAndClose reads the rest of the response body up to a small limit and closes it to reuse the connection.
+func DrainAndClose(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxDrainBytes))
+	_ = res.Body.Close()
+}
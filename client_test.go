@@ -2,7 +2,9 @@ package httpagent
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	mockhttp "github.com/karupanerura/go-mock-http-response"
@@ -57,3 +59,59 @@ func TestContextWithClient(t *testing.T) {
 		ContextWithClient(ctx, nil)
 	})
 }
+
+func TestContextWithOffline(t *testing.T) {
+	ctx := context.Background()
+	if contextOffline(ctx) {
+		t.Errorf("Initial context is invalid: %#v", ctx)
+	}
+	if !contextOffline(ContextWithOffline(ctx)) {
+		t.Errorf("Context should be offline")
+	}
+}
+
+func TestDrainAndClose(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		reader := strings.NewReader("OK")
+		body := &closeRecorder{Reader: reader}
+		DrainAndClose(&http.Response{Body: body})
+		if !body.closed {
+			t.Error("Body should be closed")
+		}
+		if reader.Len() != 0 {
+			t.Errorf("Body should be drained, but %d bytes remain", reader.Len())
+		}
+	})
+
+	t.Run("Endless", func(t *testing.T) {
+		body := &closeRecorder{Reader: zeroReader{}}
+		DrainAndClose(&http.Response{Body: body})
+		if !body.closed {
+			t.Error("Body should be closed")
+		}
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		DrainAndClose(nil)
+		DrainAndClose(&http.Response{})
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		body := &closeRecorder{Reader: strings.NewReader("Unavailable")}
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: body, Request: req}, nil
+		}))
+		agent.FallbackClient = ClientFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("OK")), Request: req}, nil
+		})
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+		if !body.closed {
+			t.Error("Discarded response body should be closed")
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package httpagent
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of Agent and the hooks and clients which have a Clock field.
+// It drives the timeouts (Agent.DefaultTimeout and WithTimeout), the retry backoff and the polling of DoAsync.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t *realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+// withClockTimeout is context.WithTimeout driven by the clock.
+func withClockTimeout(parent context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if clock == RealClock {
+		return context.WithTimeout(parent, d)
+	}
+
+	ctx := &clockTimeoutContext{Context: parent, deadline: clock.Now().Add(d), done: make(chan struct{})}
+	timer := clock.NewTimer(d)
+	go func() {
+		defer timer.Stop()
+		select {
+		case <-parent.Done():
+			ctx.cancel(parent.Err())
+		case <-timer.C():
+			ctx.cancel(context.DeadlineExceeded)
+		case <-ctx.done:
+		}
+	}()
+	return ctx, func() { ctx.cancel(context.Canceled) }
+}
+
+type clockTimeoutContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+
+	once sync.Once
+	mu   sync.Mutex
+	err  error
+}
+
+func (c *clockTimeoutContext) Deadline() (time.Time, bool) {
+	if deadline, ok := c.Context.Deadline(); ok && deadline.Before(c.deadline) {
+		return deadline, true
+	}
+	return c.deadline, true
+}
+
+func (c *clockTimeoutContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *clockTimeoutContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *clockTimeoutContext) cancel(err error) {
+	c.once.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		close(c.done)
+	})
+}
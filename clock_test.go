@@ -0,0 +1,196 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1), when: c.now.Add(d), active: true}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, t := range c.timers {
+		if t.active && !t.when.After(c.now) {
+			t.active = false
+			t.c <- c.now
+		}
+	}
+}
+
+func (c *fakeClock) ActiveTimers() (n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range c.timers {
+		if t.active {
+			n++
+		}
+	}
+	return
+}
+
+// WaitTimers waits until n timers are active.
+func (c *fakeClock) WaitTimers(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for c.ActiveTimers() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout to wait %d timers", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type fakeTimer struct {
+	clock  *fakeClock
+	c      chan time.Time
+	when   time.Time
+	active bool
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	t.active = false
+	return active
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	t.active = true
+	t.when = t.clock.now.Add(d)
+	return active
+}
+
+func TestRealClock(t *testing.T) {
+	before := time.Now()
+	if now := RealClock.Now(); now.Before(before) {
+		t.Errorf("Unexpected now: %s", now)
+	}
+
+	select {
+	case <-RealClock.After(time.Millisecond):
+	case <-time.After(3 * time.Second):
+		t.Error("After should fire")
+	}
+
+	timer := RealClock.NewTimer(time.Hour)
+	if !timer.Stop() {
+		t.Error("Timer should be active")
+	}
+	timer.Reset(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(3 * time.Second):
+		t.Error("Timer should fire")
+	}
+}
+
+func TestAgentClock(t *testing.T) {
+	agent := NewAgent(nil)
+	if agent.clock() != RealClock {
+		t.Errorf("Default clock should be RealClock, but got: %#v", agent.clock())
+	}
+
+	clock := newFakeClock()
+	agent.Clock = clock
+	if agent.WithClient(nil).clock() != clock {
+		t.Errorf("Clock should be inherited, but got: %#v", agent.WithClient(nil).clock())
+	}
+}
+
+func TestAgentClockTimeout(t *testing.T) {
+	clock := newFakeClock()
+	agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}))
+	agent.DefaultTimeout = time.Second
+	agent.Clock = clock
+
+	t.Run("DefaultTimeout", func(t *testing.T) {
+		done := make(chan error, 1)
+		go func() {
+			_, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+			done <- err
+		}()
+
+		clock.WaitTimers(t, 1)
+		clock.Advance(999 * time.Millisecond)
+		select {
+		case err := <-done:
+			t.Fatalf("Should not time out yet, but got: %#v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		clock.Advance(time.Millisecond)
+		if err := <-done; err != context.DeadlineExceeded {
+			t.Errorf("Error should be context.DeadlineExceeded, but got: %#v", err)
+		}
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		var deadline time.Time
+		agent := agent.WithClient(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			deadline, _ = req.Context().Deadline()
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}))
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		_, err := agent.Do(req.WithContext(WithTimeout(req.Context(), 500*time.Millisecond)))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if expected := clock.Now().Add(500 * time.Millisecond); !deadline.Equal(expected) {
+			t.Errorf("Deadline should be %s, but got: %s", expected, deadline)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := withClockTimeout(context.Background(), clock, time.Second)
+		cancel()
+		<-ctx.Done()
+		if err := ctx.Err(); err != context.Canceled {
+			t.Errorf("Error should be context.Canceled, but got: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package httpagent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+type CompressionStats struct {
+	compressed   int64
+	decompressed int64
+	decoded      int32
+}
+
+func (s *CompressionStats) CompressedBytes() int64 {
+	return atomic.LoadInt64(&s.compressed)
+}
+
+// DecompressedBytes returns the read bytes after decompression, or the compressed bytes if the body is not decompressed.
+func (s *CompressionStats) DecompressedBytes() int64 {
+	if atomic.LoadInt32(&s.decoded) == 0 {
+		return s.CompressedBytes()
+	}
+	return atomic.LoadInt64(&s.decompressed)
+}
+
+// Ratio returns decompressed/compressed bytes ratio. (0 if nothing is read)
+func (s *CompressionStats) Ratio() float64 {
+	compressed := s.CompressedBytes()
+	if compressed == 0 {
+		return 0
+	}
+	return float64(s.DecompressedBytes()) / float64(compressed)
+}
+
+type compressionStatsContextKeyType struct{}
+
+var compressionStatsContextKey = compressionStatsContextKeyType{}
+
+func CompressionStatsFromContext(ctx context.Context) *CompressionStats {
+	stats, _ := ctx.Value(compressionStatsContextKey).(*CompressionStats)
+	return stats
+}
+
+// CompressionRatioHook counts the bytes from the wire. It should be appended before the decompression hooks.
+type CompressionRatioHook struct{}
+
+func (h *CompressionRatioHook) Do(res *http.Response) error {
+	if res.Body == nil || res.Body == http.NoBody {
+		return nil
+	}
+
+	stats := &CompressionStats{}
+	res.Body = &countingReadCloser{ReadCloser: res.Body, n: &stats.compressed}
+	setResponseContextValue(res, compressionStatsContextKey, stats)
+	return nil
+}
+
+// countDecompressed is used by the decompression hooks to share the decompressed bytes with CompressionRatioHook.
+func countDecompressed(res *http.Response, body io.ReadCloser) io.ReadCloser {
+	if res.Request == nil {
+		return body
+	}
+
+	stats := CompressionStatsFromContext(res.Request.Context())
+	if stats == nil {
+		return body
+	}
+
+	atomic.StoreInt32(&stats.decoded, 1)
+	return &countingReadCloser{ReadCloser: body, n: &stats.decompressed}
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddInt64(r.n, int64(n))
+	return n, err
+}
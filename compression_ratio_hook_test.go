@@ -0,0 +1,74 @@
+package httpagent
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestCompressionRatioHook(t *testing.T) {
+	plain := strings.Repeat("hello ", 100)
+	compressed := mustGzip(t, []byte(plain))
+
+	do := func(t *testing.T, body []byte, hooks ...ResponseHook) (*http.Response, string) {
+		agent := NewAgent(mockhttp.NewResponseMock(http.StatusOK, nil, body).MakeClient())
+		for _, hook := range hooks {
+			agent.ResponseHooks.Append(hook)
+		}
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, string(b)
+	}
+
+	t.Run("Gzip", func(t *testing.T) {
+		res, body := do(t, compressed, &CompressionRatioHook{}, &SniffGzipResponseHook{})
+		if body != plain {
+			t.Errorf("Body should be decompressed, but got: %q", body)
+		}
+
+		stats := CompressionStatsFromContext(res.Request.Context())
+		if stats == nil {
+			t.Fatal("CompressionStats should be stored in context")
+		}
+		if stats.CompressedBytes() != int64(len(compressed)) {
+			t.Errorf("CompressedBytes should be %d, but got: %d", len(compressed), stats.CompressedBytes())
+		}
+		if stats.DecompressedBytes() != int64(len(plain)) {
+			t.Errorf("DecompressedBytes should be %d, but got: %d", len(plain), stats.DecompressedBytes())
+		}
+		if ratio := float64(len(plain)) / float64(len(compressed)); stats.Ratio() != ratio {
+			t.Errorf("Ratio should be %f, but got: %f", ratio, stats.Ratio())
+		}
+	})
+
+	t.Run("Plain", func(t *testing.T) {
+		res, _ := do(t, []byte(plain), &CompressionRatioHook{}, &SniffGzipResponseHook{})
+
+		stats := CompressionStatsFromContext(res.Request.Context())
+		if stats.CompressedBytes() != int64(len(plain)) || stats.DecompressedBytes() != int64(len(plain)) {
+			t.Errorf("Unexpected stats: %#v", stats)
+		}
+		if stats.Ratio() != 1 {
+			t.Errorf("Ratio should be 1, but got: %f", stats.Ratio())
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		stats := &CompressionStats{}
+		if stats.Ratio() != 0 {
+			t.Errorf("Ratio should be 0, but got: %f", stats.Ratio())
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package httpagent
+
+import (
+	"container/heap"
+	"context"
+	"net/http"
+	"sync"
+)
+
+type priorityContextKeyType struct{}
+
+var priorityContextKey = priorityContextKeyType{}
+
+// ContextWithPriority sets the priority of the request. The higher is admitted first. (default: 0)
+func ContextWithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityContextKey, priority)
+}
+
+func contextPriority(ctx context.Context) int {
+	priority, _ := ctx.Value(priorityContextKey).(int)
+	return priority
+}
+
+// ConcurrencyLimitClient limits the number of in-flight requests.
+// Waiting requests are admitted in priority order, and in FIFO order within the same priority.
+// The zero value does not limit the requests; use NewConcurrencyLimitClient.
+type ConcurrencyLimitClient struct {
+	Client Client
+
+	mu      sync.Mutex
+	limit   int
+	active  int
+	seq     uint64
+	waiters waiterQueue
+}
+
+var _ Client = &ConcurrencyLimitClient{}
+
+func NewConcurrencyLimitClient(client Client, n int) *ConcurrencyLimitClient {
+	if n <= 0 {
+		panic("non-positive concurrency limit")
+	}
+	return &ConcurrencyLimitClient{Client: client, limit: n}
+}
+
+// LimitConcurrency wraps the client to limit the number of in-flight requests to n.
+func LimitConcurrency(c Client, n int) Client {
+	return NewConcurrencyLimitClient(c, n)
+}
+
+func (c *ConcurrencyLimitClient) Do(req *http.Request) (*http.Response, error) {
+	if c.limit <= 0 {
+		return c.Client.Do(req)
+	}
+
+	err := c.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	defer c.release()
+
+	return c.Client.Do(req)
+}
+
+func (c *ConcurrencyLimitClient) acquire(ctx context.Context) error {
+	c.mu.Lock()
+	if c.active < c.limit && c.waiters.Len() == 0 {
+		c.active++
+		c.mu.Unlock()
+		return nil
+	}
+
+	c.seq++
+	w := &waiter{priority: contextPriority(ctx), seq: c.seq, ready: make(chan struct{})}
+	heap.Push(&c.waiters, w)
+	c.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		granted := w.index < 0
+		if !granted {
+			heap.Remove(&c.waiters, w.index)
+		}
+		c.mu.Unlock()
+
+		// pass the acquired slot to the next waiter
+		if granted {
+			c.release()
+		}
+		return ctx.Err()
+	}
+}
+
+func (c *ConcurrencyLimitClient) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.waiters.Len() == 0 {
+		c.active--
+		return
+	}
+
+	w := heap.Pop(&c.waiters).(*waiter)
+	close(w.ready)
+}
+
+func (c *ConcurrencyLimitClient) inFlight() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.active
+}
+
+func (c *ConcurrencyLimitClient) waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.waiters.Len()
+}
+
+type waiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int {
+	return len(q)
+}
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
@@ -0,0 +1,173 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func waitUntil(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestConcurrencyLimitClient(t *testing.T) {
+	t.Run("Priority", func(t *testing.T) {
+		var mu sync.Mutex
+		var order []string
+		block := make(chan struct{})
+		client := NewConcurrencyLimitClient(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			name := req.URL.Path
+			if name == "/first" {
+				<-block
+			}
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}), 1)
+
+		var wg sync.WaitGroup
+		do := func(path string, priority int) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, "http://example.com"+path, nil)
+				req = req.WithContext(ContextWithPriority(req.Context(), priority))
+				_, err := client.Do(req)
+				if err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+
+		do("/first", 0)
+		waitUntil(t, func() bool { return client.waiting() == 0 && client.inFlight() == 1 })
+		do("/low1", 0)
+		waitUntil(t, func() bool { return client.waiting() == 1 })
+		do("/low2", 0)
+		waitUntil(t, func() bool { return client.waiting() == 2 })
+		do("/high", 10)
+		waitUntil(t, func() bool { return client.waiting() == 3 })
+
+		close(block)
+		wg.Wait()
+
+		expected := []string{"/first", "/high", "/low1", "/low2"}
+		if diff := cmp.Diff(expected, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+		if n := client.inFlight(); n != 0 {
+			t.Errorf("All slots should be released, but got %d in-flight requests", n)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		block := make(chan struct{})
+		client := NewConcurrencyLimitClient(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			<-block
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}), 1)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+			_, _ = client.Do(req)
+		}()
+		waitUntil(t, func() bool { return client.waiting() == 0 && client.inFlight() == 1 })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		_, err := client.Do(req.WithContext(ctx))
+		if err != context.DeadlineExceeded {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if n := client.waiting(); n != 0 {
+			t.Errorf("Canceled waiter should be removed, but got %d waiters", n)
+		}
+
+		close(block)
+		<-done
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("The code did not panic")
+			}
+		}()
+		NewConcurrencyLimitClient(http.DefaultClient, 0)
+	})
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	var active, peak int32
+	block := make(chan struct{})
+	client := LimitConcurrency(ClientFunc(func(req *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		<-block
+		atomic.AddInt32(&active, -1)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}), 2)
+
+	agent := NewAgent(client)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	limiter := client.(*ConcurrencyLimitClient)
+	waitUntil(t, func() bool { return limiter.inFlight() == 2 && limiter.waiting() == 3 })
+	close(block)
+	wg.Wait()
+
+	if p := atomic.LoadInt32(&peak); p != 2 {
+		t.Errorf("Peak in-flight requests should be 2, but got: %d", p)
+	}
+}
+
+func TestConcurrencyLimitClientZeroValue(t *testing.T) {
+	client := &ConcurrencyLimitClient{Client: mockhttp.NewResponseMock(http.StatusOK, nil, nil).MakeClient()}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("The zero value should not block the request")
+	}
+}
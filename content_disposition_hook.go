@@ -0,0 +1,60 @@
+package httpagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var ErrUnsafeFilename = errors.New("httpagent: unsafe filename in Content-Disposition")
+
+type filenameContextKeyType struct{}
+
+var filenameContextKey = filenameContextKeyType{}
+
+// FilenameFromContext returns the filename suggested by Content-Disposition, or empty string if absent.
+func FilenameFromContext(ctx context.Context) string {
+	filename, _ := ctx.Value(filenameContextKey).(string)
+	return filename
+}
+
+type ContentDispositionHook struct{}
+
+func (h *ContentDispositionHook) Do(res *http.Response) error {
+	value := res.Header.Get("Content-Disposition")
+	if value == "" {
+		return nil
+	}
+
+	// filename* (RFC 5987) is decoded and preferred to filename by mime.ParseMediaType
+	_, params, err := mime.ParseMediaType(value)
+	if err != nil {
+		return fmt.Errorf("httpagent: invalid Content-Disposition: %w", err)
+	}
+
+	filename := params["filename"]
+	if filename == "" {
+		return nil
+	}
+	if !isSafeFilename(filename) {
+		return fmt.Errorf("%w: %q", ErrUnsafeFilename, filename)
+	}
+
+	setResponseContextValue(res, filenameContextKey, filename)
+	return nil
+}
+
+func isSafeFilename(filename string) bool {
+	if filename == "." || filename == ".." || strings.ContainsAny(filename, `/\`) {
+		return false
+	}
+	for _, r := range filename {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestContentDispositionHook(t *testing.T) {
+	newResponse := func(t *testing.T, contentDisposition string) *http.Response {
+		header := map[string]string{}
+		if contentDisposition != "" {
+			header["Content-Disposition"] = contentDisposition
+		}
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		return mockhttp.NewResponseMock(http.StatusOK, header, []byte("OK")).MakeResponse(req)
+	}
+
+	cases := map[string]struct {
+		value    string
+		expected string
+	}{
+		"Plain":     {value: `attachment; filename="report.csv"`, expected: "report.csv"},
+		"Token":     {value: `attachment; filename=report.csv`, expected: "report.csv"},
+		"RFC5987":   {value: `attachment; filename*=UTF-8''%E3%83%AC%E3%83%9D%E3%83%BC%E3%83%88.csv`, expected: "レポート.csv"},
+		"Preferred": {value: `attachment; filename="fallback.csv"; filename*=UTF-8''%C3%A9t%C3%A9.csv`, expected: "été.csv"},
+		"NoFile":    {value: `inline`, expected: ""},
+		"Missing":   {value: "", expected: ""},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			res := newResponse(t, c.value)
+			err := (&ContentDispositionHook{}).Do(res)
+			if err != nil {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			if filename := FilenameFromContext(res.Request.Context()); filename != c.expected {
+				t.Errorf("Filename should be %q, but got: %q", c.expected, filename)
+			}
+		})
+	}
+
+	for _, value := range []string{
+		`attachment; filename="../../etc/passwd"`,
+		`attachment; filename*=UTF-8''..%2F..%2Fetc%2Fpasswd`,
+		`attachment; filename="..\\evil.exe"`,
+		`attachment; filename=".."`,
+	} {
+		value := value
+		t.Run("Traversal/"+value, func(t *testing.T) {
+			res := newResponse(t, value)
+			err := (&ContentDispositionHook{}).Do(res)
+			if !errors.Is(err, ErrUnsafeFilename) {
+				t.Errorf("Unexpected error is occurred: %#v", err)
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		res := newResponse(t, `attachment; filename="unterminated`)
+		err := (&ContentDispositionHook{}).Do(res)
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+}
@@ -0,0 +1,27 @@
+package httpagent
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"net/http"
+)
+
+type ContentMD5Hook struct{}
+
+func (h *ContentMD5Hook) Do(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if _, ok := req.Header["Content-Md5"]; ok {
+		return nil
+	}
+
+	b, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+
+	sum := md5.Sum(b)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	return nil
+}
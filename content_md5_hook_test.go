@@ -0,0 +1,53 @@
+package httpagent
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestContentMD5Hook(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPut, "http://example.com/", strings.NewReader("hello world"))
+		err := (&ContentMD5Hook{}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := req.Header.Get("Content-MD5"); v != "XrY7u+Ae7tCTyyK7j1rNww==" {
+			t.Errorf("Unexpected Content-MD5: %q", v)
+		}
+
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "hello world" {
+			t.Errorf("Body should be restored, but got: %q", s)
+		}
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPut, "http://example.com/", strings.NewReader("hello world"))
+		req.Header.Set("Content-MD5", "given")
+		err := (&ContentMD5Hook{}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := req.Header.Get("Content-MD5"); v != "given" {
+			t.Errorf("Content-MD5 should not be overwritten, but got: %q", v)
+		}
+	})
+
+	t.Run("NoBody", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&ContentMD5Hook{}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := req.Header["Content-Md5"]; ok {
+			t.Errorf("Content-MD5 should not be set, but got: %#v", req.Header)
+		}
+	})
+}
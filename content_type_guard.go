@@ -0,0 +1,58 @@
+package httpagent
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+type ContentTypeError struct {
+	ContentType string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("httpagent: unexpected content type: %q", e.ContentType)
+}
+
+type ContentTypeGuardClient struct {
+	Client       Client
+	AllowedTypes []string
+}
+
+var _ Client = &ContentTypeGuardClient{}
+
+func (c *ContentTypeGuardClient) Do(req *http.Request) (*http.Response, error) {
+	res, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if !c.isAllowed(contentType) {
+		DrainAndClose(res)
+		return nil, &ContentTypeError{ContentType: contentType}
+	}
+
+	return res, nil
+}
+
+func (c *ContentTypeGuardClient) isAllowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range c.AllowedTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mediaType {
+			return true
+		}
+
+		// wildcard subtype (e.g. "text/*")
+		if strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, allowed[:len(allowed)-1]) {
+			return true
+		}
+	}
+	return false
+}
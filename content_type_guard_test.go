@@ -0,0 +1,53 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestContentTypeGuardClient(t *testing.T) {
+	newClient := func(contentType string) *ContentTypeGuardClient {
+		return &ContentTypeGuardClient{
+			Client: mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+				"Content-Type": contentType,
+			}, []byte("{}")).MakeClient(),
+			AllowedTypes: []string{"application/json", "text/*"},
+		}
+	}
+
+	for _, contentType := range []string{"application/json", "Application/JSON; charset=utf-8", "text/plain"} {
+		contentType := contentType
+		t.Run("Allowed/"+contentType, func(t *testing.T) {
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+			res, err := newClient(contentType).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("Unexpected response: %#v", res)
+			}
+		})
+	}
+
+	for _, contentType := range []string{"image/png", "application/xml", ""} {
+		contentType := contentType
+		t.Run("Disallowed/"+contentType, func(t *testing.T) {
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+			res, err := newClient(contentType).Do(req)
+			if res != nil {
+				t.Errorf("Should be no response, but got: %#v", res)
+			}
+
+			var ctErr *ContentTypeError
+			if !errors.As(err, &ctErr) {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			if ctErr.ContentType != contentType {
+				t.Errorf("ContentType should be %q, but got: %q", contentType, ctErr.ContentType)
+			}
+		})
+	}
+}
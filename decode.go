@@ -0,0 +1,67 @@
+package httpagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+func (a *Agent) DoJSON(req *http.Request, out interface{}) error {
+	return a.doDecode(req, "application/json", func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(out)
+	})
+}
+
+func (a *Agent) DoXML(req *http.Request, out interface{}) error {
+	return a.doDecode(req, "application/xml", func(r io.Reader) error {
+		return xml.NewDecoder(r).Decode(out)
+	})
+}
+
+func (a *Agent) doDecode(req *http.Request, accept string, decode func(io.Reader) error) error {
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	res, err := a.Do(req)
+	if err != nil {
+		return err
+	}
+	defer DrainAndClose(res)
+
+	if !isSuccessStatus(res.StatusCode) {
+		return &HTTPStatusError{StatusCode: res.StatusCode, Status: res.Status}
+	}
+	return decode(res.Body)
+}
+
+// NewJSONRequest returns a request with the JSON-encoded body, which is replayable for retries and redirects.
+func NewJSONRequest(ctx context.Context, method, url string, v interface{}) (*http.Request, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// DecodeJSON decodes the response body into v and closes it.
+// The error includes the status if the response is not successful.
+func DecodeJSON(res *http.Response, v interface{}) error {
+	defer DrainAndClose(res)
+
+	err := json.NewDecoder(res.Body).Decode(v)
+	if err != nil && !isSuccessStatus(res.StatusCode) {
+		return fmt.Errorf("httpagent: failed to decode the response of %s: %w", res.Status, err)
+	}
+	return err
+}
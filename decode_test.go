@@ -0,0 +1,213 @@
+package httpagent
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+type decodeTestItem struct {
+	XMLName xml.Name `json:"-" xml:"item"`
+	ID      int      `json:"id" xml:"id,attr"`
+	Name    string   `json:"name" xml:"name"`
+}
+
+func setupDecodeTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	item := decodeTestItem{ID: 1, Name: "foo"}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/error" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("X-Accept", r.Header.Get("Accept"))
+		switch r.Header.Get("Accept") {
+		case "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(item)
+		case "application/xml", "text/xml":
+			w.Header().Set("Content-Type", "application/xml")
+			_ = xml.NewEncoder(w).Encode(item)
+		default:
+			w.WriteHeader(http.StatusNotAcceptable)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestAgentDoJSON(t *testing.T) {
+	ts := setupDecodeTestServer(t)
+	agent := NewAgent(http.DefaultClient)
+
+	t.Run("OK", func(t *testing.T) {
+		var got decodeTestItem
+		err := agent.DoJSON(mustNewRequest(t, http.MethodGet, ts.URL, nil), &got)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+
+		expected := decodeTestItem{ID: 1, Name: "foo"}
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Errorf("Unexpected item: %s", diff)
+		}
+	})
+
+	t.Run("StatusError", func(t *testing.T) {
+		var got decodeTestItem
+		err := agent.DoJSON(mustNewRequest(t, http.MethodGet, ts.URL+"/error", nil), &got)
+
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if statusErr.StatusCode != http.StatusNotFound {
+			t.Errorf("StatusCode should be 404, but got: %d", statusErr.StatusCode)
+		}
+	})
+}
+
+func TestAgentDoXML(t *testing.T) {
+	ts := setupDecodeTestServer(t)
+	agent := NewAgent(http.DefaultClient)
+
+	t.Run("OK", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+
+		var got decodeTestItem
+		err := agent.DoXML(req, &got)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if accept := req.Header.Get("Accept"); accept != "application/xml" {
+			t.Errorf("Accept should be application/xml, but got: %s", accept)
+		}
+
+		expected := decodeTestItem{XMLName: xml.Name{Local: "item"}, ID: 1, Name: "foo"}
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Errorf("Unexpected item: %s", diff)
+		}
+	})
+
+	t.Run("KeepAccept", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		req.Header.Set("Accept", "text/xml")
+
+		var got decodeTestItem
+		err := agent.DoXML(req, &got)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if accept := req.Header.Get("Accept"); accept != "text/xml" {
+			t.Errorf("Accept should be text/xml, but got: %s", accept)
+		}
+	})
+
+	t.Run("StatusError", func(t *testing.T) {
+		var got decodeTestItem
+		err := agent.DoXML(mustNewRequest(t, http.MethodGet, ts.URL+"/error", nil), &got)
+
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if statusErr.StatusCode != http.StatusNotFound {
+			t.Errorf("StatusCode should be 404, but got: %d", statusErr.StatusCode)
+		}
+	})
+}
+
+func TestNewJSONRequest(t *testing.T) {
+	req, err := NewJSONRequest(context.Background(), http.MethodPost, "http://example.com/", decodeTestItem{ID: 1, Name: "foo"})
+	if err != nil {
+		t.Fatalf("Unexpected error is occurred: %#v", err)
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type should be application/json, but got: %s", ct)
+	}
+
+	const expected = `{"id":1,"name":"foo"}`
+	if req.ContentLength != int64(len(expected)) {
+		t.Errorf("ContentLength should be %d, but got: %d", len(expected), req.ContentLength)
+	}
+	for i := 0; i < 2; i++ {
+		body, err := req.GetBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := io.ReadAll(body); string(b) != expected {
+			t.Errorf("Body should be %s, but got: %s", expected, b)
+		}
+	}
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		_, err := NewJSONRequest(context.Background(), http.MethodPost, "http://example.com/", func() {})
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+}
+
+func TestDecodeJSON(t *testing.T) {
+	newResponse := func(status int, body string) (*http.Response, *closeRecorder) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		res := mockhttp.NewResponseMock(status, nil, nil).MakeResponse(req)
+		recorder := &closeRecorder{Reader: strings.NewReader(body)}
+		res.Body = recorder
+		return res, recorder
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		res, body := newResponse(http.StatusOK, `{"id":1,"name":"foo"}`)
+
+		var item decodeTestItem
+		err := DecodeJSON(res, &item)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if diff := cmp.Diff(decodeTestItem{ID: 1, Name: "foo"}, item); diff != "" {
+			t.Errorf("Unexpected item: %s", diff)
+		}
+		if !body.closed {
+			t.Error("Body should be closed")
+		}
+	})
+
+	t.Run("ErrorResponse", func(t *testing.T) {
+		res, body := newResponse(http.StatusBadGateway, "<html>Bad Gateway</html>")
+
+		var item decodeTestItem
+		err := DecodeJSON(res, &item)
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("Error should wrap *json.SyntaxError, but got: %#v", err)
+		}
+		if !strings.Contains(err.Error(), "502 Bad Gateway") {
+			t.Errorf("Error should include the status, but got: %s", err)
+		}
+		if !body.closed {
+			t.Error("Body should be closed")
+		}
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		res, _ := newResponse(http.StatusOK, "invalid")
+
+		var item decodeTestItem
+		var syntaxErr *json.SyntaxError
+		if err := DecodeJSON(res, &item); !errors.As(err, &syntaxErr) || strings.Contains(err.Error(), "200") {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package httpagent
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrTruncatedBody is returned when the gzip body ends unexpectedly or mismatches its checksum.
+var ErrTruncatedBody = errors.New("httpagent: truncated body")
+
+type DecompressHook struct{}
+
+func (h *DecompressHook) Do(res *http.Response) error {
+	if res.Body == nil || res.Body == http.NoBody {
+		return nil
+	}
+
+	var body io.ReadCloser
+	switch strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return err
+		}
+		body = &gzipReadCloser{Reader: zr, body: res.Body}
+	case "deflate":
+		zr, err := newDeflateReader(res.Body)
+		if err != nil {
+			return err
+		}
+		body = &decoderReadCloser{ReadCloser: zr, body: res.Body}
+	default:
+		return nil
+	}
+
+	res.Body = countDecompressed(res, body)
+	res.ContentLength = -1
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.Uncompressed = true
+	return nil
+}
+
+// newDeflateReader reads "deflate" content coding, which is zlib format (RFC 1950) but some servers send raw deflate (RFC 1951).
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(2)
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
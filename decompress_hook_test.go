@@ -0,0 +1,176 @@
+package httpagent
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestDecompressHook(t *testing.T) {
+	const text = "Hello, Hello, Hello, World!"
+
+	compress := func(t *testing.T, encoding string) []byte {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip":
+			w = gzip.NewWriter(&buf)
+		case "zlib":
+			w = zlib.NewWriter(&buf)
+		case "flate":
+			var err error
+			w, err = flate.NewWriter(&buf, flate.DefaultCompression)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := io.WriteString(w, text); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	cases := []struct {
+		name            string
+		contentEncoding string
+		body            func(t *testing.T) []byte
+	}{
+		{name: "Gzip", contentEncoding: "gzip", body: func(t *testing.T) []byte { return compress(t, "gzip") }},
+		{name: "DeflateZlib", contentEncoding: "Deflate", body: func(t *testing.T) []byte { return compress(t, "zlib") }},
+		{name: "DeflateRaw", contentEncoding: "deflate", body: func(t *testing.T) []byte { return compress(t, "flate") }},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			body := &closeRecorder{Reader: bytes.NewReader(c.body(t))}
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+			res := mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+				"Content-Encoding": c.contentEncoding,
+				"Content-Length":   "100",
+			}, nil).MakeResponse(req)
+			res.Body = body
+
+			err := (&DecompressHook{}).Do(res)
+			if err != nil {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			if res.Header.Get("Content-Encoding") != "" || res.Header.Get("Content-Length") != "" {
+				t.Errorf("Content-Encoding and Content-Length should be removed, but got: %#v", res.Header)
+			}
+
+			b, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := string(b); s != text {
+				t.Errorf("Body should be %s, but got: %s", text, s)
+			}
+
+			res.Body.Close()
+			if !body.closed {
+				t.Error("Underlying body should be closed")
+			}
+		})
+	}
+
+	t.Run("Identity", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		err := (&DecompressHook{}).Do(res)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "OK" {
+			t.Errorf("Body should be OK, but got: %s", s)
+		}
+	})
+
+	t.Run("CompressionRatio", func(t *testing.T) {
+		compressed := compress(t, "gzip")
+		agent := NewAgent(mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+			"Content-Encoding": "gzip",
+		}, compressed).MakeClient())
+		agent.ResponseHooks.Append(&CompressionRatioHook{})
+		agent.ResponseHooks.Append(&DecompressHook{})
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if _, err := io.ReadAll(res.Body); err != nil {
+			t.Fatal(err)
+		}
+
+		stats := CompressionStatsFromContext(res.Request.Context())
+		if stats.DecompressedBytes() != int64(len(text)) {
+			t.Errorf("DecompressedBytes should be %d, but got: %d", len(text), stats.DecompressedBytes())
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		res := mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+			"Content-Encoding": "gzip",
+		}, []byte("not gzip")).MakeResponse(req)
+
+		err := (&DecompressHook{}).Do(res)
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+	t.Run("Truncated", func(t *testing.T) {
+		compressed := compress(t, "gzip")
+		corrupted := append([]byte(nil), compressed...)
+		corrupted[len(corrupted)-8] ^= 0xff // CRC-32
+
+		cases := []struct {
+			name string
+			body []byte
+			want string
+		}{
+			{name: "Trailer", body: compressed[:len(compressed)-4], want: text},
+			{name: "Data", body: compressed[:len(compressed)/2]},
+			{name: "Checksum", body: corrupted, want: text},
+		}
+		for _, c := range cases {
+			c := c
+			t.Run(c.name, func(t *testing.T) {
+				req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+				res := mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+					"Content-Encoding": "gzip",
+				}, c.body).MakeResponse(req)
+
+				err := (&DecompressHook{}).Do(res)
+				if err != nil {
+					t.Fatalf("Unexpected error is occurred: %#v", err)
+				}
+
+				b, err := io.ReadAll(res.Body)
+				if !errors.Is(err, ErrTruncatedBody) {
+					t.Errorf("Error should be ErrTruncatedBody, but got: %#v", err)
+				}
+				if c.want != "" && string(b) != c.want {
+					t.Errorf("Partial body should be %s, but got: %s", c.want, b)
+				}
+				if !strings.HasPrefix(text, string(b)) {
+					t.Errorf("Partial body should be a prefix of %s, but got: %s", text, b)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+var ErrInsufficientTime = errors.New("httpagent: insufficient time until the deadline")
+
+// DeriveDeadlineHook limits the request to the remaining time of the context deadline minus Margin,
+// e.g. to leave time for a gateway to respond to the inbound request.
+// The timeout is applied by Agent.Do in the same way as WithTimeout.
+type DeriveDeadlineHook struct {
+	Margin time.Duration
+	Clock  Clock
+}
+
+func (h *DeriveDeadlineHook) Do(req *http.Request) error {
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return nil
+	}
+
+	clock := h.Clock
+	if clock == nil {
+		clock = RealClock
+	}
+	timeout := deadline.Sub(clock.Now()) - h.Margin
+	if timeout <= 0 {
+		return ErrInsufficientTime
+	}
+
+	// keep the shorter timeout
+	if d := contextTimeout(req.Context()); d > 0 && d < timeout {
+		return nil
+	}
+	setRequestContextValue(req, timeoutContextKey, timeout)
+	return nil
+}
@@ -0,0 +1,82 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDeriveDeadlineHook(t *testing.T) {
+	clock := newFakeClock()
+	hook := &DeriveDeadlineHook{Margin: 100 * time.Millisecond, Clock: clock}
+
+	newRequest := func(t *testing.T, remaining time.Duration) *http.Request {
+		ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(remaining))
+		t.Cleanup(cancel)
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("Ample", func(t *testing.T) {
+		req := newRequest(t, time.Second)
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if d := contextTimeout(req.Context()); d != 900*time.Millisecond {
+			t.Errorf("Timeout should be 900ms, but got: %s", d)
+		}
+	})
+
+	t.Run("ShorterTimeout", func(t *testing.T) {
+		req := newRequest(t, time.Second)
+		req = req.WithContext(WithTimeout(req.Context(), 300*time.Millisecond))
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if d := contextTimeout(req.Context()); d != 300*time.Millisecond {
+			t.Errorf("Timeout should be kept 300ms, but got: %s", d)
+		}
+	})
+
+	t.Run("NearlyExhausted", func(t *testing.T) {
+		req := newRequest(t, 50*time.Millisecond)
+		if err := hook.Do(req); err != ErrInsufficientTime {
+			t.Errorf("Error should be ErrInsufficientTime, but got: %#v", err)
+		}
+	})
+
+	t.Run("NoDeadline", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if d := contextTimeout(req.Context()); d != 0 {
+			t.Errorf("Timeout should not be set, but got: %s", d)
+		}
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			deadline, _ := req.Context().Deadline()
+			if remaining := time.Until(deadline); remaining > 500*time.Millisecond {
+				t.Errorf("Deadline should be derived, but remaining: %s", remaining)
+			}
+			return mustNewResponse(t, req.Method, req.URL.String(), nil), nil
+		}))
+		agent.RequestHooks.Append(&DeriveDeadlineHook{Margin: 9500 * time.Millisecond})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		res, err := agent.Do(req.WithContext(ctx))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+	})
+}
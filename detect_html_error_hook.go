@@ -0,0 +1,65 @@
+package httpagent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// DefaultHTMLSnippetSize is the maximum size of HTMLErrorPageError.Snippet.
+const DefaultHTMLSnippetSize = 256
+
+type HTMLErrorPageError struct {
+	StatusCode int
+	Snippet    string
+}
+
+func (e *HTMLErrorPageError) Error() string {
+	return fmt.Sprintf("httpagent: unexpected HTML error page (status=%d): %q", e.StatusCode, e.Snippet)
+}
+
+// DetectHTMLErrorHook detects an HTML error page served instead of ExpectedType (e.g. by a proxy).
+// It does nothing if ExpectedType is empty or HTML.
+type DetectHTMLErrorHook struct {
+	ExpectedType string
+	SnippetSize  int
+}
+
+func (h *DetectHTMLErrorHook) Do(res *http.Response) error {
+	if h.ExpectedType == "" || res.Body == nil || res.Body == http.NoBody {
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(h.ExpectedType); mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		return nil
+	}
+
+	size := h.SnippetSize
+	if size <= 0 {
+		size = DefaultHTMLSnippetSize
+	}
+
+	// peek the head of the body, and restore it
+	head, err := io.ReadAll(io.LimitReader(res.Body, int64(size)))
+	res.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(head), res.Body), Closer: res.Body}
+	if err != nil {
+		return err
+	}
+
+	if isHTML(head) {
+		return &HTMLErrorPageError{StatusCode: res.StatusCode, Snippet: string(head)}
+	}
+	return nil
+}
+
+func isHTML(b []byte) bool {
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	b = bytes.TrimLeft(b, " \t\r\n")
+	for _, prefix := range []string{"<!doctype", "<html"} {
+		if len(b) >= len(prefix) && bytes.EqualFold(b[:len(prefix)], []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package httpagent
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestDetectHTMLErrorHook(t *testing.T) {
+	newResponse := func(t *testing.T, body string) *http.Response {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		return mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+			"Content-Type": "application/json",
+		}, []byte(body)).MakeResponse(req)
+	}
+
+	htmlBody := "\n<!DOCTYPE html>\n<html><head><title>Login</title></head><body>" + strings.Repeat("x", 300) + "</body></html>"
+
+	t.Run("HTML", func(t *testing.T) {
+		res := newResponse(t, htmlBody)
+		err := (&DetectHTMLErrorHook{ExpectedType: "application/json"}).Do(res)
+
+		var htmlErr *HTMLErrorPageError
+		if !errors.As(err, &htmlErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if htmlErr.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode should be 200, but got: %d", htmlErr.StatusCode)
+		}
+		if expected := htmlBody[:DefaultHTMLSnippetSize]; htmlErr.Snippet != expected {
+			t.Errorf("Snippet should be %q, but got: %q", expected, htmlErr.Snippet)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != htmlBody {
+			t.Errorf("Body should be restored, but got: %s", s)
+		}
+	})
+
+	t.Run("LowerCase", func(t *testing.T) {
+		res := newResponse(t, "<html><body>error</body></html>")
+		err := (&DetectHTMLErrorHook{ExpectedType: "application/json", SnippetSize: 6}).Do(res)
+
+		var htmlErr *HTMLErrorPageError
+		if !errors.As(err, &htmlErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if htmlErr.Snippet != "<html>" {
+			t.Errorf("Snippet should be <html>, but got: %q", htmlErr.Snippet)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		res := newResponse(t, `{"html":"<html>"}`)
+		err := (&DetectHTMLErrorHook{ExpectedType: "application/json"}).Do(res)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != `{"html":"<html>"}` {
+			t.Errorf("Body should be restored, but got: %s", s)
+		}
+	})
+
+	for _, expectedType := range []string{"", "text/html; charset=utf-8"} {
+		expectedType := expectedType
+		t.Run("Skip/"+expectedType, func(t *testing.T) {
+			res := newResponse(t, htmlBody)
+			err := (&DetectHTMLErrorHook{ExpectedType: expectedType}).Do(res)
+			if err != nil {
+				t.Errorf("Unexpected error is occurred: %#v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package httpagent
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+var DefaultDigestAlgorithms = []string{"SHA-256"}
+
+var digestAlgorithms = map[string]func() hash.Hash{
+	"MD5":     md5.New,
+	"SHA":     sha1.New,
+	"SHA-256": sha256.New,
+	"SHA-512": sha512.New,
+}
+
+type DigestHeaderHook struct {
+	Algorithms []string
+}
+
+func (h *DigestHeaderHook) Do(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	algorithms := h.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = DefaultDigestAlgorithms
+	}
+
+	b, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+
+	digests := make([]string, len(algorithms))
+	for i, algorithm := range algorithms {
+		newHash, ok := digestAlgorithms[strings.ToUpper(algorithm)]
+		if !ok {
+			return fmt.Errorf("httpagent: unsupported digest algorithm: %s", algorithm)
+		}
+
+		hasher := newHash()
+		_, _ = hasher.Write(b)
+		digests[i] = strings.ToUpper(algorithm) + "=" + base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+	}
+
+	req.Header.Set("Digest", strings.Join(digests, ","))
+	return nil
+}
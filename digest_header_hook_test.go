@@ -0,0 +1,64 @@
+package httpagent
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDigestHeaderHook(t *testing.T) {
+	t.Run("SHA-256", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("hello"))
+		err := (&DigestHeaderHook{}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "SHA-256=LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ="
+		if digest := req.Header.Get("Digest"); digest != expected {
+			t.Errorf("Digest should be %s, but got: %s", expected, digest)
+		}
+
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "hello" {
+			t.Errorf("Body should be hello, but got: %s", s)
+		}
+	})
+
+	t.Run("MultiAlgorithms", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("hello"))
+		err := (&DigestHeaderHook{Algorithms: []string{"sha-256", "SHA-512"}}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "SHA-256=LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=," +
+			"SHA-512=m3HSJL1i83hdltRq0+o9czGb+8KJDKra4t/3JRlnPKcjI8PZm6XBHXx6zG4UuMXaDEZjR1wuXDre9G9zvN7AQw=="
+		if digest := req.Header.Get("Digest"); digest != expected {
+			t.Errorf("Digest should be %s, but got: %s", expected, digest)
+		}
+	})
+
+	t.Run("UnsupportedAlgorithm", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("hello"))
+		err := (&DigestHeaderHook{Algorithms: []string{"CRC32"}}).Do(req)
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+
+	t.Run("NoBody", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&DigestHeaderHook{}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if digest := req.Header.Get("Digest"); digest != "" {
+			t.Errorf("Digest should be empty, but got: %s", digest)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+type IndexedResult struct {
+	Index    int
+	Response *http.Response
+	Err      error
+}
+
+// DoAllChan sends the requests with the given concurrency and streams the results as they complete.
+// The requests not sent yet when ctx is done are reported with ctx.Err(), and the requests in flight are canceled.
+// The channel is buffered for all results and closed when all results are sent.
+func (a *Agent) DoAllChan(ctx context.Context, reqs []*http.Request, concurrency int) <-chan IndexedResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make(chan IndexedResult, len(reqs))
+	go func() {
+		defer close(results)
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, concurrency)
+		for i, req := range reqs {
+			if err := acquireSemaphore(ctx, sem); err != nil {
+				results <- IndexedResult{Index: i, Err: err}
+				continue
+			}
+
+			wg.Add(1)
+			go func(i int, req *http.Request) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				req, cancel := withCancelOf(ctx, req)
+				res, err := a.Do(req)
+				if err != nil || res.Body == nil {
+					cancel()
+				} else {
+					res.Body = releaseOnClose(res.Body, cancel, true)
+				}
+				results <- IndexedResult{Index: i, Response: res, Err: err}
+			}(i, req)
+		}
+		wg.Wait()
+	}()
+	return results
+}
+
+// withCancelOf returns a copy of the request which is also canceled when ctx is done.
+// The cancel func should be called when the response body is done.
+func withCancelOf(ctx context.Context, req *http.Request) (*http.Request, context.CancelFunc) {
+	if ctx.Done() == nil {
+		return req, nop
+	}
+
+	reqCtx, cancel := context.WithCancel(req.Context())
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-reqCtx.Done():
+		}
+	}()
+	return req.WithContext(reqCtx), cancel
+}
+
+func acquireSemaphore(ctx context.Context, sem chan struct{}) error {
+	// prefer the cancellation
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case sem <- struct{}{}:
+		return nil
+	}
+}
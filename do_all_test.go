@@ -0,0 +1,103 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAgentDoAllChan(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var inFlight, maxInFlight int32
+		block := make(chan struct{})
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+					break
+				}
+			}
+			<-block
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}))
+
+		reqs := make([]*http.Request, 10)
+		for i := range reqs {
+			reqs[i] = mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		}
+
+		results := agent.DoAllChan(context.Background(), reqs, 3)
+		close(block)
+
+		seen := map[int]bool{}
+		for result := range results {
+			if result.Err != nil {
+				t.Errorf("Unexpected error is occurred: %#v", result.Err)
+			}
+			if result.Response.Request != reqs[result.Index] {
+				t.Errorf("Result %d should be for its request", result.Index)
+			}
+			seen[result.Index] = true
+		}
+		if len(seen) != len(reqs) {
+			t.Errorf("All indices should arrive, but got: %#v", seen)
+		}
+		if max := atomic.LoadInt32(&maxInFlight); max > 3 {
+			t.Errorf("Concurrency should be limited to 3, but got: %d", max)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		reqs := []*http.Request{
+			mustNewRequest(t, http.MethodGet, "http://example.com/", nil),
+			mustNewRequest(t, http.MethodGet, "http://example.com/", nil),
+		}
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			t.Error("Request should not be sent")
+			return nil, nil
+		}))
+
+		var n int
+		for result := range agent.DoAllChan(ctx, reqs, 1) {
+			if result.Err != context.Canceled {
+				t.Errorf("Unexpected error is occurred: %#v", result.Err)
+			}
+			n++
+		}
+		if n != len(reqs) {
+			t.Errorf("All indices should arrive, but got %d results", n)
+		}
+	})
+	t.Run("CancelInFlight", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		started := make(chan struct{}, 2)
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}))
+
+		reqs := []*http.Request{
+			mustNewRequest(t, http.MethodGet, "http://example.com/", nil),
+			mustNewRequest(t, http.MethodGet, "http://example.com/", nil),
+		}
+		results := agent.DoAllChan(ctx, reqs, 2)
+		<-started
+		<-started
+		cancel()
+
+		for result := range results {
+			if result.Err != context.Canceled {
+				t.Errorf("Unexpected error is occurred: %#v", result.Err)
+			}
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("httpagent: egress quota exceeded")
+
+type egressRecord struct {
+	at   time.Time
+	size int64
+}
+
+type EgressQuotaHook struct {
+	Budget int64
+	Window time.Duration
+	Clock  Clock
+
+	mu      sync.Mutex
+	records []egressRecord
+	used    int64
+}
+
+func (h *EgressQuotaHook) Do(req *http.Request) error {
+	size, err := requestBodySize(req)
+	if err != nil {
+		return err
+	}
+
+	clock := h.Clock
+	if clock == nil {
+		clock = RealClock
+	}
+	now := clock.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// slide the window
+	start := now.Add(-h.Window)
+	i := 0
+	for ; i < len(h.records) && !h.records[i].at.After(start); i++ {
+		h.used -= h.records[i].size
+	}
+	h.records = h.records[i:]
+
+	if h.used+size > h.Budget {
+		return ErrQuotaExceeded
+	}
+	h.records = append(h.records, egressRecord{at: now, size: size})
+	h.used += size
+	return nil
+}
+
+func requestBodySize(req *http.Request) (int64, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return 0, nil
+	}
+	if req.ContentLength > 0 {
+		return req.ContentLength, nil
+	}
+
+	b, err := readRequestBody(req)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(b)), nil
+}
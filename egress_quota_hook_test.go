@@ -0,0 +1,87 @@
+package httpagent
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEgressQuotaHook(t *testing.T) {
+	t.Run("Window", func(t *testing.T) {
+		clock := newFakeClock()
+		hook := &EgressQuotaHook{Budget: 10, Window: time.Minute, Clock: clock}
+
+		newRequest := func(body string) *http.Request {
+			return mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader(body))
+		}
+
+		if err := hook.Do(newRequest("12345")); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(30 * time.Second)
+		if err := hook.Do(newRequest("12345")); err != nil {
+			t.Fatal(err)
+		}
+		if err := hook.Do(newRequest("1")); err != ErrQuotaExceeded {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if err := hook.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil)); err != nil {
+			t.Errorf("Request without body should pass, but got: %#v", err)
+		}
+
+		// the first record slides out
+		clock.Advance(30 * time.Second)
+		if err := hook.Do(newRequest("12345")); err != nil {
+			t.Errorf("Quota should recover, but got: %#v", err)
+		}
+		if err := hook.Do(newRequest("1")); err != ErrQuotaExceeded {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("UnknownLength", func(t *testing.T) {
+		hook := &EgressQuotaHook{Budget: 4, Window: time.Minute}
+
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", ioutil.NopCloser(io.MultiReader(strings.NewReader("12345"))))
+		if err := hook.Do(req); err != ErrQuotaExceeded {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "12345" {
+			t.Errorf("Body should be restored, but got: %q", s)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		hook := &EgressQuotaHook{Budget: 50, Window: time.Minute}
+
+		var mu sync.Mutex
+		var passed int
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodPost, "http://example.com/", strings.NewReader("1"))
+				if hook.Do(req) == nil {
+					mu.Lock()
+					passed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if passed != 50 {
+			t.Errorf("50 requests should pass, but got: %d", passed)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package httpagent
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+)
+
+var DefaultExpectBodyStatuses = []int{http.StatusOK}
+
+type EmptyBodyError struct {
+	StatusCode int
+}
+
+func (e *EmptyBodyError) Error() string {
+	return fmt.Sprintf("httpagent: response body is unexpectedly empty (status=%d)", e.StatusCode)
+}
+
+type ExpectBodyResponseHook struct {
+	Statuses []int
+}
+
+func (h *ExpectBodyResponseHook) Do(res *http.Response) error {
+	if !h.expectBody(res) {
+		return nil
+	}
+
+	if res.ContentLength > 0 {
+		return nil
+	}
+
+	if res.ContentLength < 0 && res.Body != nil && res.Body != http.NoBody {
+		br := bufio.NewReader(res.Body)
+		_, err := br.Peek(1)
+		res.Body = &readCloser{Reader: br, Closer: res.Body}
+		if err == nil {
+			return nil
+		}
+	}
+
+	if res.Body != nil {
+		_ = res.Body.Close()
+	}
+	return &EmptyBodyError{StatusCode: res.StatusCode}
+}
+
+func (h *ExpectBodyResponseHook) expectBody(res *http.Response) bool {
+	// legitimately empty
+	if res.StatusCode == http.StatusNoContent || res.StatusCode == http.StatusNotModified {
+		return false
+	}
+	if res.Request != nil && res.Request.Method == http.MethodHead {
+		return false
+	}
+
+	statuses := h.Statuses
+	if statuses == nil {
+		statuses = DefaultExpectBodyStatuses
+	}
+	for _, status := range statuses {
+		if status == res.StatusCode {
+			return true
+		}
+	}
+	return false
+}
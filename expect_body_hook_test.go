@@ -0,0 +1,85 @@
+package httpagent
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestExpectBodyResponseHook(t *testing.T) {
+	newResponse := func(t *testing.T, method string, status int, body string) *http.Response {
+		req := mustNewRequest(t, method, "http://example.com/", nil)
+		return mockhttp.NewResponseMock(status, nil, []byte(body)).MakeResponse(req)
+	}
+
+	t.Run("EmptyOK", func(t *testing.T) {
+		res := newResponse(t, http.MethodGet, http.StatusOK, "")
+		err := (&ExpectBodyResponseHook{}).Do(res)
+
+		var emptyErr *EmptyBodyError
+		if !errors.As(err, &emptyErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if emptyErr.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode should be 200, but got: %d", emptyErr.StatusCode)
+		}
+	})
+
+	t.Run("EmptyUnknownLength", func(t *testing.T) {
+		res := newResponse(t, http.MethodGet, http.StatusOK, "")
+		res.ContentLength = -1
+		err := (&ExpectBodyResponseHook{}).Do(res)
+
+		var emptyErr *EmptyBodyError
+		if !errors.As(err, &emptyErr) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("UnknownLength", func(t *testing.T) {
+		res := newResponse(t, http.MethodGet, http.StatusOK, "")
+		res.ContentLength = -1
+		res.Body = ioutil.NopCloser(io.MultiReader(strings.NewReader("O"), strings.NewReader("K")))
+		err := (&ExpectBodyResponseHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "OK" {
+			t.Errorf("Body should be restored, but got: %q", s)
+		}
+	})
+
+	t.Run("NoContent", func(t *testing.T) {
+		res := newResponse(t, http.MethodGet, http.StatusNoContent, "")
+		err := (&ExpectBodyResponseHook{Statuses: []int{http.StatusOK, http.StatusNoContent}}).Do(res)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Head", func(t *testing.T) {
+		res := newResponse(t, http.MethodHead, http.StatusOK, "")
+		err := (&ExpectBodyResponseHook{}).Do(res)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("NotExpected", func(t *testing.T) {
+		res := newResponse(t, http.MethodGet, http.StatusAccepted, "")
+		err := (&ExpectBodyResponseHook{}).Do(res)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+}
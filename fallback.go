@@ -0,0 +1,35 @@
+package httpagent
+
+import "net/http"
+
+// DefaultFallbackOn falls back on transport errors and 5xx responses.
+func DefaultFallbackOn(res *http.Response, err error) bool {
+	return err != nil || res.StatusCode >= 500
+}
+
+func (a *Agent) fallback(req *http.Request, res *http.Response, err error) (*http.Response, error) {
+	fallbackOn := a.FallbackOn
+	if fallbackOn == nil {
+		fallbackOn = DefaultFallbackOn
+	}
+	if !fallbackOn(res, err) {
+		return res, err
+	}
+
+	next := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		// cannot rewind the body
+		if req.GetBody == nil {
+			return res, err
+		}
+
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return res, err
+		}
+		next.Body = body
+	}
+
+	DrainAndClose(res)
+	return a.send(a.FallbackClient, next)
+}
@@ -0,0 +1,82 @@
+package httpagent
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestAgentFallbackClient(t *testing.T) {
+	unavailable := mockhttp.NewResponseMock(http.StatusServiceUnavailable, nil, []byte("Unavailable")).MakeClient()
+
+	t.Run("Fallback", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		agent := NewAgent(unavailable)
+		agent.FallbackClient = http.DefaultClient
+
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		shouldBeOK(t, agent, req, 1)
+	})
+
+	t.Run("RewindBody", func(t *testing.T) {
+		var primaryBody, fallbackBody string
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			primaryBody = string(b)
+			return mockhttp.NewResponseMock(http.StatusServiceUnavailable, nil, nil).MakeResponse(req), nil
+		}))
+		agent.FallbackClient = ClientFunc(func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			fallbackBody = string(b)
+			return mockhttp.NewResponseMock(http.StatusOK, nil, nil).MakeResponse(req), nil
+		})
+
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("hello"))
+		res, err := agent.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode should be 200, but got: %d", res.StatusCode)
+		}
+		if primaryBody != "hello" || fallbackBody != "hello" {
+			t.Errorf("Body should be sent to both clients, but got: primary=%q fallback=%q", primaryBody, fallbackBody)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection refused")
+		}))
+		agent.FallbackClient = http.DefaultClient
+
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		shouldBeOK(t, agent, req, 1)
+	})
+
+	t.Run("NotMatched", func(t *testing.T) {
+		agent := NewAgent(unavailable)
+		agent.FallbackClient = ClientFunc(func(req *http.Request) (*http.Response, error) {
+			t.Error("Fallback client should not be called")
+			return nil, errors.New("unreachable")
+		})
+		agent.FallbackOn = func(res *http.Response, err error) bool {
+			return err != nil
+		}
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if res.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("StatusCode should be 503, but got: %d", res.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package httpagent
+
+import (
+	"net/http"
+)
+
+type ForceChunkedHook struct{}
+
+func (h *ForceChunkedHook) Do(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	req.Header.Del("Content-Length")
+	return nil
+}
@@ -0,0 +1,59 @@
+package httpagent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestForceChunkedHook(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		fmt.Fprintf(w, "%s:%d:%s", strings.Join(r.TransferEncoding, ","), r.ContentLength, b)
+	}))
+	t.Cleanup(ts.Close)
+
+	do := func(t *testing.T, agent *Agent, req *http.Request) string {
+		res, err := agent.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	agent := NewAgent(http.DefaultClient)
+	agent.RequestHooks.Append(&ForceChunkedHook{})
+
+	t.Run("Chunked", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, ts.URL, strings.NewReader("hello"))
+		if s := do(t, agent, req); s != "chunked:-1:hello" {
+			t.Errorf("Body should be sent as chunked, but got: %s", s)
+		}
+	})
+
+	t.Run("WithoutHook", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, ts.URL, strings.NewReader("hello"))
+		if s := do(t, DefaultAgent, req); s != ":5:hello" {
+			t.Errorf("Body should be sent with Content-Length, but got: %s", s)
+		}
+	})
+
+	t.Run("NoBody", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		if s := do(t, agent, req); s != ":0:" {
+			t.Errorf("Request should not be chunked, but got: %s", s)
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewFormRequest returns a request with the form-urlencoded body, which is replayable for retries and redirects.
+func NewFormRequest(ctx context.Context, method, url string, values url.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
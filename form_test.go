@@ -0,0 +1,47 @@
+package httpagent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNewFormRequest(t *testing.T) {
+	values := url.Values{}
+	values.Set("name", "foo bar")
+	values.Add("tag", "a&b")
+	values.Add("tag", "c")
+
+	req, err := NewFormRequest(context.Background(), http.MethodPost, "http://example.com/", values)
+	if err != nil {
+		t.Fatalf("Unexpected error is occurred: %#v", err)
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type should be application/x-www-form-urlencoded, but got: %s", ct)
+	}
+
+	const expected = "name=foo+bar&tag=a%26b&tag=c"
+	if req.ContentLength != int64(len(expected)) {
+		t.Errorf("ContentLength should be %d, but got: %d", len(expected), req.ContentLength)
+	}
+	if b, _ := io.ReadAll(req.Body); string(b) != expected {
+		t.Errorf("Body should be %s, but got: %s", expected, b)
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(body); string(b) != expected {
+		t.Errorf("Body from GetBody should be %s, but got: %s", expected, b)
+	}
+
+	t.Run("InvalidURL", func(t *testing.T) {
+		_, err := NewFormRequest(context.Background(), http.MethodPost, "://invalid", values)
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+}
@@ -5,4 +5,5 @@ go 1.18
 require (
 	github.com/google/go-cmp v0.5.8
 	github.com/karupanerura/go-mock-http-response v0.0.0-20171201120521-7c242a447d45
+	golang.org/x/time v0.3.0
 )
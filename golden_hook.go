@@ -0,0 +1,90 @@
+package httpagent
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// GoldenHook writes the request and the response in a stable format for golden-file testing.
+// The request body is read from GetBody, so it is omitted if the request body is not replayable.
+type GoldenHook struct {
+	Writer io.Writer
+
+	// Redact masks the values of the headers. (nil means DefaultRedactHeaders, and empty means nothing)
+	Redact []string
+}
+
+func (h *GoldenHook) Do(res *http.Response) error {
+	var buf bytes.Buffer
+	if req := res.Request; req != nil {
+		buf.WriteString("--- request\n")
+		buf.WriteString(req.Method + " " + req.URL.String() + "\n")
+		h.writeHeader(&buf, req.Header)
+		buf.WriteString("\n")
+
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				buf.WriteString("(body is not replayable)\n")
+			} else {
+				body, err := req.GetBody()
+				if err != nil {
+					return err
+				}
+				b, err := io.ReadAll(body)
+				_ = body.Close()
+				if err != nil {
+					return err
+				}
+				writeGoldenBody(&buf, b)
+			}
+		}
+	}
+
+	b, err := readResponseBody(res)
+	if err != nil {
+		return err
+	}
+	buf.WriteString("--- response\n")
+	buf.WriteString(res.Status + "\n")
+	h.writeHeader(&buf, res.Header)
+	buf.WriteString("\n")
+	writeGoldenBody(&buf, b)
+
+	_, err = buf.WriteTo(h.Writer)
+	return err
+}
+
+func (h *GoldenHook) writeHeader(buf *bytes.Buffer, header http.Header) {
+	keys := redactHeaders(h.Redact)
+	redact := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		redact[http.CanonicalHeaderKey(key)] = true
+	}
+
+	names := make([]string, 0, len(header))
+	for key := range header {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	for _, key := range names {
+		for _, value := range header[key] {
+			if redact[http.CanonicalHeaderKey(key)] {
+				value = redactedValue
+			}
+			buf.WriteString(key + ": " + value + "\n")
+		}
+	}
+}
+
+func writeGoldenBody(buf *bytes.Buffer, b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	buf.Write(b)
+	if b[len(b)-1] != '\n' {
+		buf.WriteString("\n")
+	}
+}
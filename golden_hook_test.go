@@ -0,0 +1,102 @@
+package httpagent
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+const goldenHookExpected = `--- request
+POST http://example.com/items?q=1
+Authorization: REDACTED
+Content-Type: application/json
+X-Multi: a
+X-Multi: b
+
+{"name":"foo"}
+--- response
+201 Created
+Content-Length: 8
+Content-Type: application/json
+Set-Cookie: REDACTED
+
+{"id":1}
+`
+
+func TestGoldenHook(t *testing.T) {
+	t.Run("Golden", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/items?q=1", strings.NewReader(`{"name":"foo"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Add("X-Multi", "a")
+		req.Header.Add("X-Multi", "b")
+
+		res := mockhttp.NewResponseMock(http.StatusCreated, map[string]string{
+			"Content-Type": "application/json",
+			"Set-Cookie":   "session=secret",
+		}, []byte(`{"id":1}`)).MakeResponse(req)
+
+		buf := &bytes.Buffer{}
+		err := (&GoldenHook{Writer: buf, Redact: []string{"authorization", "Set-Cookie"}}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); got != goldenHookExpected {
+			t.Errorf("Unexpected golden output:\n%s", got)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != `{"id":1}` {
+			t.Errorf("Response body should be restored, but got: %s", s)
+		}
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Request header should not be modified, but got: %#v", req.Header)
+		}
+	})
+
+	t.Run("DefaultRedact", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Cookie", "session=secret")
+
+		for _, c := range []struct {
+			name     string
+			redact   []string
+			redacted bool
+		}{
+			{name: "Nil", redact: nil, redacted: true},
+			{name: "Empty", redact: []string{}, redacted: false},
+		} {
+			res := mockhttp.NewResponseMock(http.StatusOK, nil, nil).MakeResponse(req)
+			buf := &bytes.Buffer{}
+			err := (&GoldenHook{Writer: buf, Redact: c.redact}).Do(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); strings.Contains(got, "secret") == c.redacted {
+				t.Errorf("%s: Unexpected golden output:\n%s", c.name, got)
+			}
+		}
+	})
+
+	t.Run("NotReplayable", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", io.MultiReader(strings.NewReader("body")))
+		res := mockhttp.NewResponseMock(http.StatusOK, nil, nil).MakeResponse(req)
+
+		buf := &bytes.Buffer{}
+		err := (&GoldenHook{Writer: buf}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := buf.String(); !strings.Contains(got, "(body is not replayable)\n") {
+			t.Errorf("Unexpected golden output:\n%s", got)
+		}
+	})
+}
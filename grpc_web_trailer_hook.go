@@ -0,0 +1,88 @@
+package httpagent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+var ErrInvalidGRPCWebFrame = errors.New("httpagent: invalid gRPC-Web frame")
+
+const grpcWebTrailerFlag = 0x80
+
+type grpcWebTrailerContextKeyType struct{}
+
+var grpcWebTrailerContextKey = grpcWebTrailerContextKeyType{}
+
+func GRPCWebTrailerFromContext(ctx context.Context) http.Header {
+	trailer, _ := ctx.Value(grpcWebTrailerContextKey).(http.Header)
+	return trailer
+}
+
+// GRPCWebTrailerHook extracts the trailer frame from gRPC-Web (binary) response body.
+// The message frames are kept in the body as is.
+type GRPCWebTrailerHook struct{}
+
+func (h *GRPCWebTrailerHook) Do(res *http.Response) error {
+	contentType := res.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "application/grpc-web") || strings.HasPrefix(contentType, "application/grpc-web-text") {
+		return nil
+	}
+
+	b, err := readResponseBody(res)
+	if err != nil {
+		return err
+	}
+
+	messages := make([]byte, 0, len(b))
+	trailer := http.Header{}
+	for rest := b; len(rest) != 0; {
+		if len(rest) < 5 {
+			return ErrInvalidGRPCWebFrame
+		}
+		flags, length := rest[0], binary.BigEndian.Uint32(rest[1:5])
+		if uint64(len(rest)-5) < uint64(length) {
+			return ErrInvalidGRPCWebFrame
+		}
+		frame, payload := rest[:5+length], rest[5:5+length]
+		rest = rest[5+length:]
+
+		if flags&grpcWebTrailerFlag == 0 {
+			messages = append(messages, frame...)
+			continue
+		}
+
+		err = parseGRPCWebTrailer(payload, trailer)
+		if err != nil {
+			return err
+		}
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(messages))
+	res.ContentLength = int64(len(messages))
+	res.Header.Del("Content-Length")
+	res.Trailer = trailer
+	setResponseContextValue(res, grpcWebTrailerContextKey, trailer)
+	return nil
+}
+
+func parseGRPCWebTrailer(payload []byte, trailer http.Header) error {
+	// terminate the header block for textproto
+	r := textproto.NewReader(bufio.NewReader(io.MultiReader(bytes.NewReader(payload), strings.NewReader("\r\n\r\n"))))
+	header, err := r.ReadMIMEHeader()
+	if err != nil {
+		return ErrInvalidGRPCWebFrame
+	}
+	for key, values := range header {
+		for _, value := range values {
+			trailer.Add(key, value)
+		}
+	}
+	return nil
+}
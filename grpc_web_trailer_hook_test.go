@@ -0,0 +1,87 @@
+package httpagent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func grpcWebFrame(flags byte, payload []byte) []byte {
+	frame := make([]byte, 5, 5+len(payload))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	return append(frame, payload...)
+}
+
+func TestGRPCWebTrailerHook(t *testing.T) {
+	newResponse := func(t *testing.T, contentType string, body []byte) *http.Response {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", nil)
+		return mockhttp.NewResponseMock(http.StatusOK, map[string]string{"Content-Type": contentType}, body).MakeResponse(req)
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		message := grpcWebFrame(0x00, []byte("message"))
+		body := append(append([]byte{}, message...), grpcWebFrame(0x80, []byte("grpc-status: 0\r\ngrpc-message: OK\r\n"))...)
+		res := newResponse(t, "application/grpc-web+proto", body)
+
+		err := (&GRPCWebTrailerHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		trailer := GRPCWebTrailerFromContext(res.Request.Context())
+		if trailer.Get("Grpc-Status") != "0" || trailer.Get("Grpc-Message") != "OK" {
+			t.Errorf("Unexpected trailer: %#v", trailer)
+		}
+		if res.Trailer.Get("Grpc-Status") != "0" {
+			t.Errorf("Unexpected res.Trailer: %#v", res.Trailer)
+		}
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, message) {
+			t.Errorf("Body should be the message frame, but got: %#v", b)
+		}
+		if res.ContentLength != int64(len(message)) {
+			t.Errorf("ContentLength should be %d, but got: %d", len(message), res.ContentLength)
+		}
+		if v := res.Header.Get("Content-Length"); v != "" {
+			t.Errorf("Content-Length should be removed, but got: %q", v)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		body := grpcWebFrame(0x80, []byte("grpc-status: 0\r\n"))
+		res := newResponse(t, "application/grpc-web", body[:len(body)-3])
+
+		err := (&GRPCWebTrailerHook{}).Do(res)
+		if err != ErrInvalidGRPCWebFrame {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("NotGRPCWeb", func(t *testing.T) {
+		res := newResponse(t, "application/json", []byte("{}"))
+
+		err := (&GRPCWebTrailerHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "{}" {
+			t.Errorf("Body should be untouched, but got: %q", s)
+		}
+		if trailer := GRPCWebTrailerFromContext(res.Request.Context()); trailer != nil {
+			t.Errorf("Trailer should not be stored, but got: %#v", trailer)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package httpagent
+
+import (
+	"crypto/sha256"
+	"hash"
+	"net/http"
+	"sync"
+)
+
+// HashChainHook maintains a running hash over request and response bodies: H_n = hash(H_{n-1} + body).
+// Register RequestHook and ResponseHook to the agent to chain both directions.
+type HashChainHook struct {
+	Hash func() hash.Hash
+
+	mu   sync.Mutex
+	head []byte
+}
+
+func (h *HashChainHook) RequestHook() RequestHook {
+	return RequestHookFunc(func(req *http.Request) error {
+		body, err := readRequestBody(req)
+		if err != nil {
+			return err
+		}
+
+		h.chain(body)
+		return nil
+	})
+}
+
+func (h *HashChainHook) ResponseHook() ResponseHook {
+	return ResponseHookFunc(func(res *http.Response) error {
+		body, err := readResponseBody(res)
+		if err != nil {
+			return err
+		}
+
+		h.chain(body)
+		return nil
+	})
+}
+
+// Head returns the current chain head, or nil if nothing is chained yet.
+func (h *HashChainHook) Head() []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.head == nil {
+		return nil
+	}
+	return append([]byte(nil), h.head...)
+}
+
+func (h *HashChainHook) chain(body []byte) {
+	newHash := h.Hash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hasher := newHash()
+	_, _ = hasher.Write(h.head)
+	_, _ = hasher.Write(body)
+	h.head = hasher.Sum(nil)
+}
@@ -0,0 +1,89 @@
+package httpagent
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHashChainHook(t *testing.T) {
+	sum := func(prev []byte, body string) []byte {
+		h := sha256.New()
+		h.Write(prev)
+		h.Write([]byte(body))
+		return h.Sum(nil)
+	}
+
+	t.Run("Chain", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		hook := &HashChainHook{}
+		agent := NewAgent(http.DefaultClient)
+		agent.RequestHooks.Append(hook.RequestHook())
+		agent.ResponseHooks.Append(hook.ResponseHook())
+
+		if head := hook.Head(); head != nil {
+			t.Errorf("Head should be nil at first, but got: %x", head)
+		}
+
+		var expected []byte
+		req := mustNewRequest(t, http.MethodPost, ts.URL, strings.NewReader("hello"))
+		shouldBeOK(t, agent, req, 1)
+		expected = sum(sum(expected, "hello"), "OK: count=1")
+		if head := hook.Head(); !bytes.Equal(head, expected) {
+			t.Errorf("Head should be %x, but got: %x", expected, head)
+		}
+
+		req = mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		shouldBeOK(t, agent, req, 2)
+		expected = sum(sum(expected, ""), "OK: count=2")
+		if head := hook.Head(); !bytes.Equal(head, expected) {
+			t.Errorf("Head should be %x, but got: %x", expected, head)
+		}
+	})
+
+	t.Run("RestoreBody", func(t *testing.T) {
+		hook := &HashChainHook{}
+
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("hello"))
+		err := hook.RequestHook().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []string{"Body", "GetBody"} {
+			body := req.Body
+			if name == "GetBody" {
+				body, err = req.GetBody()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			b, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := string(b); s != "hello" {
+				t.Errorf("%s should be hello, but got: %s", name, s)
+			}
+		}
+
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		err = hook.ResponseHook().Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "OK" {
+			t.Errorf("Body should be OK, but got: %s", s)
+		}
+	})
+}
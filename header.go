@@ -0,0 +1,56 @@
+package httpagent
+
+import (
+	"net/http"
+	"strings"
+)
+
+// splitQuoted splits s by sep ignoring separators in quoted-strings.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	var quoted, escaped bool
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == sep:
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+
+	s = s[1 : len(s)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// redactHeader returns a copy of the header with the values of the keys masked.
+func redactHeader(header http.Header, keys []string) http.Header {
+	header = header.Clone()
+	for _, key := range keys {
+		values := header.Values(key)
+		for i := range values {
+			values[i] = redactedValue
+		}
+	}
+	return header
+}
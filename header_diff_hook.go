@@ -0,0 +1,59 @@
+package httpagent
+
+import (
+	"net/http"
+)
+
+func HeaderDiffHook(base, target http.Header) RequestHook {
+	// canonicalize keys so that a non-canonical key is not both set and removed
+	base, target = canonicalHeader(base), canonicalHeader(target)
+
+	set := http.Header{}
+	for key, values := range target {
+		if !equalStrings(base[key], values) {
+			set[key] = values
+		}
+	}
+
+	var remove []string
+	for key := range base {
+		if _, ok := target[key]; !ok {
+			remove = append(remove, key)
+		}
+	}
+
+	if len(set) == 0 && len(remove) == 0 {
+		return NopRequestHook
+	}
+
+	return RequestHookFunc(func(req *http.Request) error {
+		for key, values := range set {
+			req.Header[key] = append([]string(nil), values...)
+		}
+		for _, key := range remove {
+			req.Header.Del(key)
+		}
+		return nil
+	})
+}
+
+func canonicalHeader(header http.Header) http.Header {
+	canonical := make(http.Header, len(header))
+	for key, values := range header {
+		key = http.CanonicalHeaderKey(key)
+		canonical[key] = append(canonical[key], values...)
+	}
+	return canonical
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
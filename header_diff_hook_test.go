@@ -0,0 +1,80 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHeaderDiffHook(t *testing.T) {
+	t.Run("Diff", func(t *testing.T) {
+		base := http.Header{}
+		base.Set("Keep", "same")
+		base.Set("Change", "before")
+		base.Set("Remove", "gone")
+		base.Add("Multi", "a")
+
+		target := http.Header{}
+		target.Set("Keep", "same")
+		target.Set("Change", "after")
+		target.Set("New", "added")
+		target.Add("Multi", "a")
+		target.Add("Multi", "b")
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header = base.Clone()
+
+		err := HeaderDiffHook(base, target).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(target, req.Header); diff != "" {
+			t.Errorf("Headers should match the target: %s", diff)
+		}
+	})
+
+	t.Run("NonCanonical", func(t *testing.T) {
+		base := http.Header{}
+		base.Set("X-Foo", "before")
+
+		target := http.Header{"x-foo": {"after"}}
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header = base.Clone()
+
+		err := HeaderDiffHook(base, target).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(http.Header{"X-Foo": {"after"}}, req.Header); diff != "" {
+			t.Errorf("Headers should match the target: %s", diff)
+		}
+	})
+
+	t.Run("NoDiff", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Foo", "bar")
+		if hook := HeaderDiffHook(header, header.Clone()); hook != NopRequestHook {
+			t.Errorf("Should be NopRequestHook, but got: %#v", hook)
+		}
+	})
+
+	t.Run("KeepOthers", func(t *testing.T) {
+		base := http.Header{}
+		base.Set("Foo", "bar")
+		target := http.Header{}
+		target.Set("Foo", "baz")
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Other", "value")
+
+		err := HeaderDiffHook(base, target).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Header.Get("Foo") != "baz" || req.Header.Get("Other") != "value" {
+			t.Errorf("Unexpected headers: %#v", req.Header)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"sort"
+)
+
+type HeaderField struct {
+	Name  string
+	Value string
+}
+
+type headerListContextKeyType struct{}
+
+var headerListContextKey = headerListContextKeyType{}
+
+func HeaderListFromContext(ctx context.Context) []HeaderField {
+	headers, _ := ctx.Value(headerListContextKey).([]HeaderField)
+	return headers
+}
+
+// HeaderListHook records the response headers as a list in a deterministic order.
+// It is NOT the order on the wire: net/http does not expose the original order across header names nor the original casing,
+// so names are canonicalized and ordered as configured by Order, and the rest of names are sorted after them.
+// Only the order of values for each name is preserved as received.
+type HeaderListHook struct {
+	// Order is the order of header names to record first (e.g. the order of signed headers)
+	Order []string
+}
+
+func (h *HeaderListHook) Do(res *http.Response) error {
+	names := make([]string, 0, len(res.Header))
+	seen := make(map[string]bool, len(h.Order))
+	for _, name := range h.Order {
+		name = http.CanonicalHeaderKey(name)
+		if _, ok := res.Header[name]; ok && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+
+	rest := make([]string, 0, len(res.Header)-len(names))
+	for name := range res.Header {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	names = append(names, rest...)
+
+	var headers []HeaderField
+	for _, name := range names {
+		for _, value := range res.Header[name] {
+			headers = append(headers, HeaderField{Name: name, Value: value})
+		}
+	}
+
+	setResponseContextValue(res, headerListContextKey, headers)
+	return nil
+}
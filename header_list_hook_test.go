@@ -0,0 +1,71 @@
+package httpagent
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHeaderListHook(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Signed", "c")
+		w.Header().Add("X-Signed", "a")
+		w.Header().Add("X-Signed", "b")
+		w.Header().Set("X-Another", "1")
+		w.Header().Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+		fmt.Fprint(w, "OK")
+	}))
+	t.Cleanup(ts.Close)
+
+	agent := NewAgent(http.DefaultClient)
+	agent.ResponseHooks.Append(&HeaderListHook{})
+
+	res, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	expected := []HeaderField{
+		{Name: "Content-Length", Value: "2"},
+		{Name: "Content-Type", Value: "text/plain; charset=utf-8"},
+		{Name: "Date", Value: "Mon, 02 Jan 2006 15:04:05 GMT"},
+		{Name: "X-Another", Value: "1"},
+		{Name: "X-Signed", Value: "c"},
+		{Name: "X-Signed", Value: "a"},
+		{Name: "X-Signed", Value: "b"},
+	}
+	if diff := cmp.Diff(expected, HeaderListFromContext(res.Request.Context())); diff != "" {
+		t.Errorf("Unexpected headers: %s", diff)
+	}
+}
+
+func TestHeaderListHookOrder(t *testing.T) {
+	hook := &HeaderListHook{Order: []string{"x-signed", "Date", "X-Missing", "X-Signed"}}
+	res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+	res.Header = http.Header{
+		"Content-Type": {"text/plain"},
+		"Date":         {"Mon, 02 Jan 2006 15:04:05 GMT"},
+		"X-Another":    {"1"},
+		"X-Signed":     {"c", "a"},
+	}
+
+	err := hook.Do(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []HeaderField{
+		{Name: "X-Signed", Value: "c"},
+		{Name: "X-Signed", Value: "a"},
+		{Name: "Date", Value: "Mon, 02 Jan 2006 15:04:05 GMT"},
+		{Name: "Content-Type", Value: "text/plain"},
+		{Name: "X-Another", Value: "1"},
+	}
+	if diff := cmp.Diff(expected, HeaderListFromContext(res.Request.Context())); diff != "" {
+		t.Errorf("Unexpected headers: %s", diff)
+	}
+}
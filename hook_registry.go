@@ -0,0 +1,129 @@
+package httpagent
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+type registeredHook struct {
+	name         string
+	requestHook  RequestHook
+	responseHook ResponseHook
+	enabled      bool
+}
+
+type HookRegistry struct {
+	mu    sync.RWMutex
+	hooks []*registeredHook
+}
+
+func NewHookRegistry() *HookRegistry {
+	return &HookRegistry{}
+}
+
+func (r *HookRegistry) RegisterRequestHook(name string, hook RequestHook) {
+	if hook == nil {
+		panic("nil hook")
+	}
+	r.register(&registeredHook{name: name, requestHook: hook, enabled: true})
+}
+
+func (r *HookRegistry) RegisterResponseHook(name string, hook ResponseHook) {
+	if hook == nil {
+		panic("nil hook")
+	}
+	r.register(&registeredHook{name: name, responseHook: hook, enabled: true})
+}
+
+func (r *HookRegistry) register(hook *registeredHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range r.hooks {
+		if h.name == hook.name {
+			panic(fmt.Sprintf("duplicate hook name: %s", hook.name))
+		}
+	}
+	r.hooks = append(r.hooks, hook)
+}
+
+func (r *HookRegistry) Enable(name string) bool {
+	return r.setEnabled(name, true)
+}
+
+func (r *HookRegistry) Disable(name string) bool {
+	return r.setEnabled(name, false)
+}
+
+func (r *HookRegistry) setEnabled(name string, enabled bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range r.hooks {
+		if h.name == name {
+			h.enabled = enabled
+			return true
+		}
+	}
+	return false
+}
+
+func (r *HookRegistry) Enabled(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, h := range r.hooks {
+		if h.name == name {
+			return h.enabled
+		}
+	}
+	return false
+}
+
+func (r *HookRegistry) RequestHooks() *RequestHooks {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := NewRequestHooks()
+	for _, h := range r.hooks {
+		if h.enabled && h.requestHook != nil {
+			hooks.Append(h.requestHook)
+		}
+	}
+	return hooks
+}
+
+func (r *HookRegistry) ResponseHooks() *ResponseHooks {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := NewResponseHooks()
+	for _, h := range r.hooks {
+		if h.enabled && h.responseHook != nil {
+			hooks.Append(h.responseHook)
+		}
+	}
+	return hooks
+}
+
+func (r *HookRegistry) Clone() *HookRegistry {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := make([]*registeredHook, len(r.hooks))
+	for i, h := range r.hooks {
+		hook := *h
+		hooks[i] = &hook
+	}
+	return &HookRegistry{hooks: hooks}
+}
+
+func (r *HookRegistry) doRequest(req *http.Request) error {
+	return r.RequestHooks().Do(req)
+}
+
+func (r *HookRegistry) doResponse(res *http.Response) error {
+	return r.ResponseHooks().Do(res)
+}
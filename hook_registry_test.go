@@ -0,0 +1,114 @@
+package httpagent
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestHookRegistry(t *testing.T) {
+	t.Run("EnableDisable", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		buf := &bytes.Buffer{}
+		agent := NewAgent(http.DefaultClient)
+		agent.HookRegistry.RegisterRequestHook("dumper", &RequestDumperHook{Writer: buf})
+
+		if !agent.HookRegistry.Enabled("dumper") {
+			t.Error("Registered hook should be enabled")
+		}
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL, nil), 1)
+		if buf.Len() == 0 {
+			t.Error("Dumper hook should run when enabled")
+		}
+
+		if !agent.HookRegistry.Disable("dumper") {
+			t.Error("Disable should find the hook")
+		}
+		buf.Reset()
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL, nil), 2)
+		if buf.Len() != 0 {
+			t.Errorf("Dumper hook should not run when disabled, but got: %s", buf.String())
+		}
+
+		if !agent.HookRegistry.Enable("dumper") {
+			t.Error("Enable should find the hook")
+		}
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL, nil), 3)
+		if buf.Len() == 0 {
+			t.Error("Dumper hook should run when re-enabled")
+		}
+	})
+
+	t.Run("Response", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		var called int
+		agent := NewAgent(http.DefaultClient)
+		agent.HookRegistry.RegisterResponseHook("counter", ResponseHookFunc(func(res *http.Response) error {
+			called++
+			return nil
+		}))
+
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL, nil), 1)
+		agent.HookRegistry.Disable("counter")
+		shouldBeOK(t, agent, mustNewRequest(t, http.MethodGet, ts.URL, nil), 2)
+		if called != 1 {
+			t.Errorf("Response hook should be called at once, but it called %d times", called)
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		registry := NewHookRegistry()
+		if registry.Enable("unknown") || registry.Disable("unknown") || registry.Enabled("unknown") {
+			t.Error("Unknown hook should not be found")
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		registry := NewHookRegistry()
+		registry.RegisterRequestHook("foo", NopRequestHook)
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("The code did not panic")
+			}
+		}()
+		registry.RegisterResponseHook("foo", NopResponseHook)
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		registry := NewHookRegistry()
+		registry.RegisterRequestHook("foo", NopRequestHook)
+
+		clone := registry.Clone()
+		clone.Disable("foo")
+		if !registry.Enabled("foo") {
+			t.Error("Original registry should not be affected by the clone")
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		registry := NewHookRegistry()
+		registry.RegisterRequestHook("foo", RequestHookFunc(func(req *http.Request) error {
+			return nil
+		}))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				registry.Disable("foo")
+				registry.Enable("foo")
+			}()
+			go func() {
+				defer wg.Done()
+				req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+				_ = registry.doRequest(req)
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,47 @@
+package httpagent
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives an observation per request. The status is 0 if the request fails.
+type MetricsRecorder interface {
+	ObserveRequest(method string, status int, dur time.Duration, err error)
+}
+
+// RequestMetricsRecorder is an optional interface of MetricsRecorder to receive the request instead of the method,
+// e.g. to classify the observations by TagsFromContext(req.Context()).
+type RequestMetricsRecorder interface {
+	ObserveRequestContext(req *http.Request, status int, dur time.Duration, err error)
+}
+
+type MetricsRecorderFunc func(method string, status int, dur time.Duration, err error)
+
+func (f MetricsRecorderFunc) ObserveRequest(method string, status int, dur time.Duration, err error) {
+	f(method, status, dur, err)
+}
+
+// InstrumentClient wraps the client to record the latency of Do for each request.
+func InstrumentClient(c Client, rec MetricsRecorder) Client {
+	if rec == nil {
+		panic("nil recorder")
+	}
+
+	return ClientFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		res, err := c.Do(req)
+		dur := time.Since(start)
+
+		var status int
+		if res != nil {
+			status = res.StatusCode
+		}
+		if r, ok := rec.(RequestMetricsRecorder); ok {
+			r.ObserveRequestContext(req, status, dur, err)
+		} else {
+			rec.ObserveRequest(req.Method, status, dur, err)
+		}
+		return res, err
+	})
+}
@@ -0,0 +1,99 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+type observation struct {
+	method string
+	status int
+	dur    time.Duration
+	err    error
+}
+
+func TestInstrumentClient(t *testing.T) {
+	var observations []observation
+	rec := MetricsRecorderFunc(func(method string, status int, dur time.Duration, err error) {
+		observations = append(observations, observation{method: method, status: status, dur: dur, err: err})
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		observations = nil
+		client := InstrumentClient(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			time.Sleep(10 * time.Millisecond)
+			return mockhttp.NewResponseMock(http.StatusNotFound, nil, nil).MakeResponse(req), nil
+		}), rec)
+
+		agent := NewAgent(client)
+		res, err := agent.Do(mustNewRequest(t, http.MethodPut, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+
+		if len(observations) != 1 {
+			t.Fatalf("Should be observed once, but got: %#v", observations)
+		}
+		o := observations[0]
+		if o.method != http.MethodPut || o.status != http.StatusNotFound || o.err != nil {
+			t.Errorf("Unexpected observation: %#v", o)
+		}
+		if o.dur < 10*time.Millisecond {
+			t.Errorf("Latency should be measured around Do, but got: %s", o.dur)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		observations = nil
+		expected := errors.New("oops")
+		client := InstrumentClient(ClientFunc(func(*http.Request) (*http.Response, error) {
+			return nil, expected
+		}), rec)
+
+		_, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != expected {
+			t.Errorf("Error should be %#v, but got: %#v", expected, err)
+		}
+
+		if len(observations) != 1 {
+			t.Fatalf("Should be observed once, but got: %#v", observations)
+		}
+		if o := observations[0]; o.method != http.MethodGet || o.status != 0 || o.err != expected {
+			t.Errorf("Unexpected observation: %#v", o)
+		}
+	})
+}
+
+type tagRecorder struct {
+	MetricsRecorderFunc
+	tags []map[string]string
+}
+
+func (r *tagRecorder) ObserveRequestContext(req *http.Request, status int, dur time.Duration, err error) {
+	r.tags = append(r.tags, TagsFromContext(req.Context()))
+}
+
+func TestInstrumentClientRequestMetricsRecorder(t *testing.T) {
+	rec := &tagRecorder{MetricsRecorderFunc: func(method string, status int, dur time.Duration, err error) {
+		t.Error("ObserveRequest should not be called")
+	}}
+	agent := NewAgent(InstrumentClient(mockhttp.NewResponseMock(http.StatusOK, nil, nil).MakeClient(), rec))
+
+	req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+	res, err := agent.Do(req.WithContext(ContextWithTag(req.Context(), "priority", "critical")))
+	if err != nil {
+		t.Fatalf("Unexpected error is occurred: %#v", err)
+	}
+	res.Body.Close()
+
+	expected := []map[string]string{{"priority": "critical"}}
+	if diff := cmp.Diff(expected, rec.tags); diff != "" {
+		t.Errorf("Tags should be observed: %s", diff)
+	}
+}
@@ -0,0 +1,54 @@
+package httpagent
+
+import (
+	"net/http"
+	"time"
+)
+
+type AgentOption func(*Agent)
+
+func WithAgentClient(client Client) AgentOption {
+	return func(a *Agent) {
+		a.Client = client
+	}
+}
+
+func WithAgentDefaultTimeout(d time.Duration) AgentOption {
+	return func(a *Agent) {
+		a.DefaultTimeout = d
+	}
+}
+
+// DefaultJSONErrorBodyBytes is the maximum bytes of the body captured into HTTPStatusError by NewJSONAgent.
+const DefaultJSONErrorBodyBytes = 1024
+
+// NewJSONAgent returns an agent for a typical JSON API.
+// Requests with a relative URL are resolved against baseURL, and non-2xx responses are HTTPStatusError.
+func NewJSONAgent(baseURL string, opts ...AgentOption) *Agent {
+	agent := NewAgent(http.DefaultClient)
+	agent.BaseURL = baseURL
+	agent.DefaultHeader.Set("Accept", "application/json")
+	agent.RequestHooks.Append(RequestHookFunc(setJSONContentType))
+	agent.ResponseHooks.Append(&StatusErrorHook{MaxBodyBytes: DefaultJSONErrorBodyBytes})
+	for _, opt := range opts {
+		opt(agent)
+	}
+	return agent
+}
+
+func setJSONContentType(req *http.Request) error {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return nil
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if _, ok := req.Header["Content-Type"]; ok {
+		return nil
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	return nil
+}
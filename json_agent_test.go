@@ -0,0 +1,99 @@
+package httpagent
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewJSONAgent(t *testing.T) {
+	type item struct {
+		ID          int    `json:"id"`
+		Name        string `json:"name"`
+		ContentType string `json:"content_type,omitempty"`
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/json" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/items/1":
+			_ = json.NewEncoder(w).Encode(item{ID: 1, Name: "foo"})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/items":
+			var v item
+			if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			v.ID = 2
+			v.ContentType = r.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(v)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	agent := NewJSONAgent(ts.URL+"/v1/", WithAgentDefaultTimeout(time.Second))
+	if agent.DefaultTimeout != time.Second {
+		t.Errorf("DefaultTimeout should be 1s, but got: %s", agent.DefaultTimeout)
+	}
+
+	t.Run("GET", func(t *testing.T) {
+		var got item
+		err := agent.DoJSON(mustNewRequest(t, http.MethodGet, "items/1", nil), &got)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if diff := cmp.Diff(item{ID: 1, Name: "foo"}, got); diff != "" {
+			t.Errorf("Unexpected item: %s", diff)
+		}
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		var got item
+		err := agent.DoJSON(mustNewRequest(t, http.MethodPost, "items", strings.NewReader(`{"name":"bar"}`)), &got)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if diff := cmp.Diff(item{ID: 2, Name: "bar", ContentType: "application/json"}, got); diff != "" {
+			t.Errorf("Unexpected item: %s", diff)
+		}
+	})
+
+	t.Run("StatusError", func(t *testing.T) {
+		var got item
+		err := agent.DoJSON(mustNewRequest(t, http.MethodGet, "items/3", nil), &got)
+
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if statusErr.StatusCode != http.StatusNotFound {
+			t.Errorf("StatusCode should be 404, but got: %d", statusErr.StatusCode)
+		}
+		if s := string(statusErr.Body); s != `{"error":"not found"}` {
+			t.Errorf("Body should be captured, but got: %s", s)
+		}
+	})
+
+	t.Run("WithAgentClient", func(t *testing.T) {
+		client := &http.Client{}
+		agent := NewJSONAgent(ts.URL, WithAgentClient(client))
+		if agent.Client != client {
+			t.Errorf("Client should be replaced, but got: %#v", agent.Client)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package httpagent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type jsonValueContextKeyType struct{}
+
+var jsonValueContextKey = jsonValueContextKeyType{}
+
+// JSONValueFromContext returns the value decoded by JSONNumberResponseHook.
+// Numbers in the value are json.Number to avoid the precision loss of float64.
+func JSONValueFromContext(ctx context.Context) interface{} {
+	return ctx.Value(jsonValueContextKey)
+}
+
+type JSONNumberResponseHook struct{}
+
+func (h *JSONNumberResponseHook) Do(res *http.Response) error {
+	b, err := readResponseBody(res)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return fmt.Errorf("httpagent: invalid JSON response: %w", err)
+	}
+
+	setResponseContextValue(res, jsonValueContextKey, v)
+	return nil
+}
@@ -0,0 +1,72 @@
+package httpagent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestJSONNumberResponseHook(t *testing.T) {
+	newResponse := func(t *testing.T, body string) *http.Response {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		return mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+			"Content-Type": "application/json",
+		}, []byte(body)).MakeResponse(req)
+	}
+
+	t.Run("Int64", func(t *testing.T) {
+		// 2^63-1 cannot be represented as float64
+		body := `{"id":9223372036854775807}`
+		res := newResponse(t, body)
+		err := (&JSONNumberResponseHook{}).Do(res)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+
+		v, ok := JSONValueFromContext(res.Request.Context()).(map[string]interface{})
+		if !ok {
+			t.Fatalf("Unexpected value: %#v", JSONValueFromContext(res.Request.Context()))
+		}
+		n, ok := v["id"].(json.Number)
+		if !ok {
+			t.Fatalf("id should be json.Number, but got: %#v", v["id"])
+		}
+		id, err := n.Int64()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != 9223372036854775807 {
+			t.Errorf("id should be 9223372036854775807, but got: %d", id)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != body {
+			t.Errorf("Body should be restored, but got: %s", s)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		res := newResponse(t, "")
+		err := (&JSONNumberResponseHook{}).Do(res)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if v := JSONValueFromContext(res.Request.Context()); v != nil {
+			t.Errorf("Value should be nil, but got: %#v", v)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		res := newResponse(t, "{")
+		err := (&JSONNumberResponseHook{}).Do(res)
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Logger receives a structured log line as alternating keys and values.
+type Logger interface {
+	Log(keyvals ...interface{})
+}
+
+type LoggerFunc func(keyvals ...interface{})
+
+func (f LoggerFunc) Log(keyvals ...interface{}) {
+	f(keyvals...)
+}
+
+type requestStartContextKeyType struct{}
+
+var requestStartContextKey = requestStartContextKeyType{}
+
+// RequestLogHook logs the request in a line, and records the start time for ResponseLogHook.
+type RequestLogHook struct {
+	Logger Logger
+	Clock  Clock
+
+	// Redact masks the values of the headers. (nil means DefaultRedactHeaders, and empty means nothing)
+	Redact []string
+
+	// Tags are the keys of the tags set by ContextWithTag to log.
+	Tags []string
+}
+
+func (h *RequestLogHook) Do(req *http.Request) error {
+	clock := h.Clock
+	if clock == nil {
+		clock = RealClock
+	}
+	setRequestContextValue(req, requestStartContextKey, clock.Now())
+
+	keyvals := []interface{}{
+		"msg", "request",
+		"method", req.Method,
+		"url", req.URL.Redacted(),
+		"header", redactHeader(req.Header, redactHeaders(h.Redact)),
+	}
+	keyvals = appendTags(req.Context(), keyvals, h.Tags)
+
+	h.Logger.Log(keyvals...)
+	return nil
+}
+
+// ResponseLogHook logs the response in a line. The duration is logged if RequestLogHook is used for the request.
+type ResponseLogHook struct {
+	Logger Logger
+	Clock  Clock
+
+	// Redact masks the values of the headers. (nil means DefaultRedactHeaders, and empty means nothing)
+	Redact []string
+
+	// Tags are the keys of the tags set by ContextWithTag to log.
+	Tags []string
+}
+
+func (h *ResponseLogHook) Do(res *http.Response) error {
+	keyvals := []interface{}{"msg", "response"}
+	if req := res.Request; req != nil {
+		keyvals = append(keyvals, "method", req.Method, "url", req.URL.Redacted())
+		if start, ok := req.Context().Value(requestStartContextKey).(time.Time); ok {
+			clock := h.Clock
+			if clock == nil {
+				clock = RealClock
+			}
+			keyvals = append(keyvals, "duration", clock.Now().Sub(start))
+		}
+	}
+	keyvals = append(keyvals,
+		"status", res.StatusCode,
+		"header", redactHeader(res.Header, redactHeaders(h.Redact)),
+	)
+	if res.Request != nil {
+		keyvals = appendTags(res.Request.Context(), keyvals, h.Tags)
+	}
+
+	h.Logger.Log(keyvals...)
+	return nil
+}
+
+// appendTags appends the tags of the keys which are set to the context.
+func appendTags(ctx context.Context, keyvals []interface{}, keys []string) []interface{} {
+	if len(keys) == 0 {
+		return keyvals
+	}
+
+	tags := TagsFromContext(ctx)
+	for _, key := range keys {
+		if value, ok := tags[key]; ok {
+			keyvals = append(keyvals, key, value)
+		}
+	}
+	return keyvals
+}
@@ -0,0 +1,38 @@
+package httpagent
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+type HeaderValueTooLongError struct {
+	Header    string
+	Length    int
+	MaxLength int
+}
+
+func (e *HeaderValueTooLongError) Error() string {
+	return fmt.Sprintf("httpagent: value of %s header is too long: %d > %d", e.Header, e.Length, e.MaxLength)
+}
+
+type MaxHeaderValueHook struct {
+	MaxLength int
+}
+
+func (h *MaxHeaderValueHook) Do(req *http.Request) error {
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range req.Header[key] {
+			if len(value) > h.MaxLength {
+				return &HeaderValueTooLongError{Header: key, Length: len(value), MaxLength: h.MaxLength}
+			}
+		}
+	}
+	return nil
+}
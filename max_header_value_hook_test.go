@@ -0,0 +1,54 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMaxHeaderValueHook(t *testing.T) {
+	hook := &MaxHeaderValueHook{MaxLength: 16}
+
+	t.Run("OK", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Foo", strings.Repeat("a", 16))
+		err := hook.Do(req)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("TooLong", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Foo", "short")
+		req.Header.Add("Cookie", "short")
+		req.Header.Add("Cookie", strings.Repeat("a", 17))
+		err := hook.Do(req)
+
+		var tooLong *HeaderValueTooLongError
+		if !errors.As(err, &tooLong) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if tooLong.Header != "Cookie" || tooLong.Length != 17 || tooLong.MaxLength != 16 {
+			t.Errorf("Unexpected error: %#v", tooLong)
+		}
+	})
+
+	t.Run("WithAgent", func(t *testing.T) {
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			t.Error("Request should not be sent")
+			return nil, nil
+		}))
+		agent.RequestHooks.Append(hook)
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("Foo", strings.Repeat("a", 17))
+		_, err := agent.Do(req)
+
+		var tooLong *HeaderValueTooLongError
+		if !errors.As(err, &tooLong) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package httpagent
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+)
+
+var ErrNoClientCert = errors.New("httpagent: no client certificate is configured")
+
+// NewMTLSClient returns a client which presents the given certificate on TLS handshakes.
+// The transport is cloned from http.DefaultTransport.
+func NewMTLSClient(cert tls.Certificate) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	return &http.Client{Transport: transport}
+}
+
+// RequireClientCertHook fails HTTPS requests fast if Client has no client certificate configured.
+// Only *http.Transport (or nil as http.DefaultTransport) is inspectable; the others are treated as no client certificate.
+type RequireClientCertHook struct {
+	Client *http.Client
+}
+
+func (h *RequireClientCertHook) Do(req *http.Request) error {
+	if req.URL.Scheme != "https" {
+		return nil
+	}
+	if !hasClientCert(h.Client) {
+		return ErrNoClientCert
+	}
+	return nil
+}
+
+func hasClientCert(client *http.Client) bool {
+	if client == nil {
+		return false
+	}
+
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	t, ok := transport.(*http.Transport)
+	if !ok || t.TLSClientConfig == nil {
+		return false
+	}
+
+	config := t.TLSClientConfig
+	return len(config.Certificates) != 0 || config.GetClientCertificate != nil
+}
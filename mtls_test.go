@@ -0,0 +1,58 @@
+package httpagent
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireClientCertHook(t *testing.T) {
+	// borrow the test certificate of httptest
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(ts.Close)
+	cert := ts.TLS.Certificates[0]
+
+	getter := &http.Transport{TLSClientConfig: &tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &cert, nil
+		},
+	}}
+	opaque := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("unreachable")
+	})
+
+	cases := map[string]struct {
+		client  *http.Client
+		url     string
+		wantErr bool
+	}{
+		"WithCert":        {client: NewMTLSClient(cert), url: "https://example.com/"},
+		"WithCertGetter":  {client: &http.Client{Transport: getter}, url: "https://example.com/"},
+		"WithoutCert":     {client: ts.Client(), url: "https://example.com/", wantErr: true},
+		"DefaultClient":   {client: http.DefaultClient, url: "https://example.com/", wantErr: true},
+		"NilClient":       {client: nil, url: "https://example.com/", wantErr: true},
+		"OpaqueTransport": {client: &http.Client{Transport: opaque}, url: "https://example.com/", wantErr: true},
+		"PlainHTTP":       {client: http.DefaultClient, url: "http://example.com/"},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			err := (&RequireClientCertHook{Client: c.client}).Do(mustNewRequest(t, http.MethodGet, c.url, nil))
+			if c.wantErr {
+				if !errors.Is(err, ErrNoClientCert) {
+					t.Errorf("Unexpected error is occurred: %#v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error is occurred: %#v", err)
+			}
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
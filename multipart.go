@@ -0,0 +1,72 @@
+package httpagent
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+type MultipartBuilder struct {
+	// Boundary is used as the multipart boundary if not empty. (random by default)
+	Boundary string
+
+	parts []func(*multipart.Writer) error
+}
+
+func NewMultipartBuilder() *MultipartBuilder {
+	return &MultipartBuilder{}
+}
+
+func (b *MultipartBuilder) AddField(name, value string) *MultipartBuilder {
+	b.parts = append(b.parts, func(w *multipart.Writer) error {
+		return w.WriteField(name, value)
+	})
+	return b
+}
+
+func (b *MultipartBuilder) AddFile(fieldName, fileName string, r io.Reader) *MultipartBuilder {
+	b.parts = append(b.parts, func(w *multipart.Writer) error {
+		pw, err := w.CreateFormFile(fieldName, fileName)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(pw, r)
+		return err
+	})
+	return b
+}
+
+func (b *MultipartBuilder) Build(ctx context.Context, method, url string) (*http.Request, error) {
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	if b.Boundary != "" {
+		err := w.SetBoundary(b.Boundary)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, part := range b.parts {
+		err := part(w)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	body := buf.Bytes()
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return req, nil
+}
@@ -0,0 +1,84 @@
+package httpagent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMultipartBuilder(t *testing.T) {
+	t.Run("PinnedBoundary", func(t *testing.T) {
+		builder := NewMultipartBuilder().
+			AddField("name", "karupa").
+			AddFile("file", "hello.txt", strings.NewReader("hello"))
+		builder.Boundary = "test-boundary"
+
+		req, err := builder.Build(context.Background(), http.MethodPost, "http://example.com/upload")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ct := req.Header.Get("Content-Type"); ct != "multipart/form-data; boundary=test-boundary" {
+			t.Errorf("Unexpected Content-Type: %s", ct)
+		}
+
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "--test-boundary\r\n" +
+			"Content-Disposition: form-data; name=\"name\"\r\n" +
+			"\r\n" +
+			"karupa\r\n" +
+			"--test-boundary\r\n" +
+			"Content-Disposition: form-data; name=\"file\"; filename=\"hello.txt\"\r\n" +
+			"Content-Type: application/octet-stream\r\n" +
+			"\r\n" +
+			"hello\r\n" +
+			"--test-boundary--\r\n"
+		if s := string(b); s != expected {
+			t.Errorf("Unexpected body: %q", s)
+		}
+		if req.ContentLength != int64(len(expected)) {
+			t.Errorf("ContentLength should be %d, but got: %d", len(expected), req.ContentLength)
+		}
+
+		body, err := req.GetBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err = io.ReadAll(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != expected {
+			t.Errorf("Unexpected body by GetBody: %q", s)
+		}
+	})
+
+	t.Run("RandomBoundary", func(t *testing.T) {
+		req, err := NewMultipartBuilder().AddField("a", "b").Build(context.Background(), http.MethodPost, "http://example.com/")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = req.ParseMultipartForm(1024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a := req.FormValue("a"); a != "b" {
+			t.Errorf("Field a should be b, but got: %q", a)
+		}
+	})
+
+	t.Run("InvalidBoundary", func(t *testing.T) {
+		builder := NewMultipartBuilder()
+		builder.Boundary = "invalid boundary!\n"
+		_, err := builder.Build(context.Background(), http.MethodPost, "http://example.com/")
+		if err == nil {
+			t.Error("Should be error")
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package httpagent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+func DecodeNDJSONTyped[T any](res *http.Response, fn func(T) error) error {
+	defer res.Body.Close()
+
+	r := bufio.NewReader(res.Body)
+	for lineNo := 1; ; lineNo++ {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if b := bytes.TrimSpace(line); len(b) != 0 {
+			var v T
+			if err := json.Unmarshal(b, &v); err != nil {
+				return fmt.Errorf("httpagent: invalid NDJSON at line %d: %w", lineNo, err)
+			}
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
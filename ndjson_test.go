@@ -0,0 +1,106 @@
+package httpagent
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestDecodeNDJSONTyped(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		body := &closeRecorder{Reader: strings.NewReader("{\"id\":1,\"name\":\"foo\"}\n  \n{\"id\":2,\"name\":\"bar\"}\r\n\n{\"id\":3,\"name\":\"baz\"}")}
+		res := &http.Response{StatusCode: http.StatusOK, Body: body}
+
+		var got []item
+		err := DecodeNDJSONTyped(res, func(v item) error {
+			got = append(got, v)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []item{{1, "foo"}, {2, "bar"}, {3, "baz"}}
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Errorf("Unexpected items: %s", diff)
+		}
+		if !body.closed {
+			t.Error("Body should be closed")
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		res := &http.Response{StatusCode: http.StatusOK, Body: pr}
+
+		go func() {
+			for _, line := range []string{"{\"id\":1}\n", "{\"id\":2}\n"} {
+				_, _ = pw.Write([]byte(line))
+			}
+			_ = pw.Close()
+		}()
+
+		var ids []int
+		err := DecodeNDJSONTyped(res, func(v item) error {
+			ids = append(ids, v.ID)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(ids, []int{1, 2}) {
+			t.Errorf("Unexpected ids: %#v", ids)
+		}
+	})
+
+	t.Run("CallbackError", func(t *testing.T) {
+		body := &closeRecorder{Reader: strings.NewReader("{\"id\":1}\n{\"id\":2}\n")}
+		res := &http.Response{StatusCode: http.StatusOK, Body: body}
+
+		expectedErr := errors.New("stop")
+		var called int
+		err := DecodeNDJSONTyped(res, func(v item) error {
+			called++
+			return expectedErr
+		})
+		if err != expectedErr {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if called != 1 {
+			t.Errorf("Callback should be called at once, but it called %d times", called)
+		}
+		if !body.closed {
+			t.Error("Body should be closed")
+		}
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		res := &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader("{\"id\":1}\n{oops\n"))}
+		err := DecodeNDJSONTyped(res, func(v item) error {
+			return nil
+		})
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+}
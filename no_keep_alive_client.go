@@ -0,0 +1,17 @@
+package httpagent
+
+import (
+	"net/http"
+)
+
+// NewNoKeepAliveClient returns a client which uses a fresh connection for each request.
+// The transport is cloned from the given one, or http.DefaultTransport if nil.
+func NewNoKeepAliveClient(transport *http.Transport) Client {
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+
+	transport = transport.Clone()
+	transport.DisableKeepAlives = true
+	return &http.Client{Transport: transport}
+}
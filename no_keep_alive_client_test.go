@@ -0,0 +1,54 @@
+package httpagent
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNewNoKeepAliveClient(t *testing.T) {
+	setup := func(t *testing.T) (*httptest.Server, *int32) {
+		var conns int32
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		ts.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt32(&conns, 1)
+			}
+		}
+		ts.Start()
+		t.Cleanup(ts.Close)
+		return ts, &conns
+	}
+
+	doTimes := func(t *testing.T, agent *Agent, u string, n int) {
+		for i := 0; i < n; i++ {
+			res, err := agent.Do(mustNewRequest(t, http.MethodGet, u, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			DrainAndClose(res)
+		}
+	}
+
+	t.Run("NoKeepAlive", func(t *testing.T) {
+		ts, conns := setup(t)
+		doTimes(t, NewAgent(NewNoKeepAliveClient(nil)), ts.URL, 3)
+		if n := atomic.LoadInt32(conns); n != 3 {
+			t.Errorf("Each request should use a fresh connection, but got %d connections", n)
+		}
+	})
+
+	t.Run("KeepAlive", func(t *testing.T) {
+		ts, conns := setup(t)
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		t.Cleanup(transport.CloseIdleConnections)
+		doTimes(t, NewAgent(&http.Client{Transport: transport}), ts.URL, 3)
+		if n := atomic.LoadInt32(conns); n != 1 {
+			t.Errorf("Connection should be reused, but got %d connections", n)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package httpagent
+
+import (
+	"fmt"
+	"net/http"
+)
+
+var DefaultNonEmptyBodyMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch}
+
+type EmptyRequestBodyError struct {
+	Method string
+}
+
+func (e *EmptyRequestBodyError) Error() string {
+	return fmt.Sprintf("httpagent: request body is empty for %s", e.Method)
+}
+
+type NonEmptyBodyHook struct {
+	Methods []string
+}
+
+func (h *NonEmptyBodyHook) Do(req *http.Request) error {
+	if !h.isTarget(req.Method) {
+		return nil
+	}
+
+	size, err := requestBodySize(req)
+	if err != nil {
+		return err
+	}
+	if size == 0 {
+		return &EmptyRequestBodyError{Method: req.Method}
+	}
+	return nil
+}
+
+func (h *NonEmptyBodyHook) isTarget(method string) bool {
+	methods := h.Methods
+	if methods == nil {
+		methods = DefaultNonEmptyBodyMethods
+	}
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
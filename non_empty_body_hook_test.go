@@ -0,0 +1,68 @@
+package httpagent
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNonEmptyBodyHook(t *testing.T) {
+	hook := &NonEmptyBodyHook{}
+
+	t.Run("Empty", func(t *testing.T) {
+		for _, body := range []string{"", "unknown"} {
+			var req *http.Request
+			if body == "" {
+				req = mustNewRequest(t, http.MethodPost, "http://example.com/", nil)
+			} else {
+				// unknown length and empty
+				req = mustNewRequest(t, http.MethodPost, "http://example.com/", ioutil.NopCloser(strings.NewReader("")))
+			}
+
+			err := hook.Do(req)
+			var emptyErr *EmptyRequestBodyError
+			if !errors.As(err, &emptyErr) {
+				t.Errorf("Unexpected error is occurred: %#v", err)
+			} else if emptyErr.Method != http.MethodPost {
+				t.Errorf("Method should be POST, but got: %s", emptyErr.Method)
+			}
+		}
+	})
+
+	t.Run("NonEmpty", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", ioutil.NopCloser(strings.NewReader("body")))
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "body" {
+			t.Errorf("Body should be restored, but got: %q", s)
+		}
+	})
+
+	t.Run("PassThrough", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			req := mustNewRequest(t, method, "http://example.com/", nil)
+			err := hook.Do(req)
+			if err != nil {
+				t.Errorf("Unexpected error is occurred for %s: %#v", method, err)
+			}
+		}
+	})
+
+	t.Run("CustomMethods", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodDelete, "http://example.com/", nil)
+		err := (&NonEmptyBodyHook{Methods: []string{http.MethodDelete}}).Do(req)
+		var emptyErr *EmptyRequestBodyError
+		if !errors.As(err, &emptyErr) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+}
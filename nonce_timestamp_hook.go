@@ -0,0 +1,68 @@
+package httpagent
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultTimestampHeader = "X-Date"
+	DefaultNonceHeader     = "X-Nonce"
+)
+
+type Signer interface {
+	Sign(req *http.Request, timestamp time.Time, nonce string) error
+}
+
+type SignerFunc func(req *http.Request, timestamp time.Time, nonce string) error
+
+func (f SignerFunc) Sign(req *http.Request, timestamp time.Time, nonce string) error {
+	return f(req, timestamp, nonce)
+}
+
+type NonceTimestampHook struct {
+	TimestampHeader string
+	NonceHeader     string
+	Clock           Clock
+	Signer          Signer
+}
+
+func (h *NonceTimestampHook) Do(req *http.Request) error {
+	clock := h.Clock
+	if clock == nil {
+		clock = RealClock
+	}
+	timestamp := clock.Now().UTC()
+
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+
+	timestampHeader := h.TimestampHeader
+	if timestampHeader == "" {
+		timestampHeader = DefaultTimestampHeader
+	}
+	nonceHeader := h.NonceHeader
+	if nonceHeader == "" {
+		nonceHeader = DefaultNonceHeader
+	}
+	req.Header.Set(timestampHeader, timestamp.Format(http.TimeFormat))
+	req.Header.Set(nonceHeader, nonce)
+
+	if h.Signer != nil {
+		return h.Signer.Sign(req, timestamp, nonce)
+	}
+	return nil
+}
+
+func newNonce() (string, error) {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,79 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNonceTimestampHook(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		hook := &NonceTimestampHook{Clock: newFakeClock()}
+
+		nonces := map[string]bool{}
+		for i := 0; i < 100; i++ {
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+			err := hook.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if date := req.Header.Get("X-Date"); date != "Sat, 01 Jan 2022 00:00:00 GMT" {
+				t.Errorf("Unexpected timestamp: %s", date)
+			}
+
+			nonce := req.Header.Get("X-Nonce")
+			if len(nonce) != 32 {
+				t.Errorf("Unexpected nonce: %s", nonce)
+			}
+			if nonces[nonce] {
+				t.Errorf("Nonce should be unique, but got duplicated: %s", nonce)
+			}
+			nonces[nonce] = true
+		}
+	})
+
+	t.Run("Signer", func(t *testing.T) {
+		clock := newFakeClock()
+		var gotTimestamp time.Time
+		var gotNonce string
+		hook := &NonceTimestampHook{
+			TimestampHeader: "Date",
+			NonceHeader:     "Nonce",
+			Clock:           clock,
+			Signer: SignerFunc(func(req *http.Request, timestamp time.Time, nonce string) error {
+				gotTimestamp, gotNonce = timestamp, nonce
+				req.Header.Set("Signature", nonce+"@"+timestamp.Format(time.RFC3339))
+				return nil
+			}),
+		}
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !gotTimestamp.Equal(clock.Now()) {
+			t.Errorf("Unexpected timestamp: %s", gotTimestamp)
+		}
+		if gotNonce != req.Header.Get("Nonce") {
+			t.Errorf("Nonce should be passed to the signer, but got: %s", gotNonce)
+		}
+		if sig := req.Header.Get("Signature"); sig != gotNonce+"@2022-01-01T00:00:00Z" {
+			t.Errorf("Unexpected signature: %s", sig)
+		}
+	})
+
+	t.Run("SignerError", func(t *testing.T) {
+		expectedErr := errors.New("oops")
+		hook := &NonceTimestampHook{Signer: SignerFunc(func(req *http.Request, timestamp time.Time, nonce string) error {
+			return expectedErr
+		})}
+
+		err := hook.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != expectedErr {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+module github.com/karupanerura/go-httpagent/otelhttpagent
+
+go 1.18
+
+require (
+	github.com/karupanerura/go-httpagent v0.0.0
+	github.com/karupanerura/go-mock-http-response v0.0.0-20171201120521-7c242a447d45
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/sdk v1.14.0
+	go.opentelemetry.io/otel/trace v1.14.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/time v0.3.0 // indirect
+)
+
+replace github.com/karupanerura/go-httpagent => ../
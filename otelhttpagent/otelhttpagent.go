@@ -0,0 +1,108 @@
+// Package otelhttpagent provides OpenTelemetry tracing for httpagent.
+package otelhttpagent
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/karupanerura/go-httpagent"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/karupanerura/go-httpagent/otelhttpagent"
+
+// RequestHook starts a client span and injects the trace context into the request headers.
+// The span is carried by the request context, and should be ended by ResponseHook.
+type RequestHook struct {
+	Tracer     trace.Tracer
+	Propagator propagation.TextMapPropagator
+}
+
+func (h *RequestHook) Do(req *http.Request) error {
+	ctx := startSpan(h.Tracer, h.Propagator, req)
+	setRequestContext(req, ctx)
+	return nil
+}
+
+// setRequestContext replaces the context of the request for the following hooks, as the request hooks of httpagent do.
+func setRequestContext(req *http.Request, ctx context.Context) {
+	*req = *req.WithContext(ctx)
+}
+
+// ResponseHook ends the span started by RequestHook with the status code.
+// Note that the span is never ended if the request fails before the response hooks, so prefer NewTracingClient for it.
+type ResponseHook struct{}
+
+func (h *ResponseHook) Do(res *http.Response) error {
+	if res.Request == nil {
+		return nil
+	}
+
+	span := trace.SpanFromContext(res.Request.Context())
+	endSpan(span, res, nil)
+	return nil
+}
+
+// NewTracingClient wraps the client to trace the whole round trip, including the errors.
+func NewTracingClient(base httpagent.Client, tracer trace.Tracer) httpagent.Client {
+	return httpagent.ClientFunc(func(req *http.Request) (*http.Response, error) {
+		// clone not to modify the headers of the caller
+		req = req.Clone(req.Context())
+		ctx := startSpan(tracer, nil, req)
+		req = req.WithContext(ctx)
+
+		res, err := base.Do(req)
+		endSpan(trace.SpanFromContext(ctx), res, err)
+		return res, err
+	})
+}
+
+func startSpan(tracer trace.Tracer, propagator propagation.TextMapPropagator, req *http.Request) context.Context {
+	if tracer == nil {
+		tracer = otel.Tracer(instrumentationName)
+	}
+	if propagator == nil {
+		propagator = otel.GetTextMapPropagator()
+	}
+
+	ctx, _ := tracer.Start(req.Context(), req.Method+" "+req.URL.Hostname(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethod(req.Method),
+			semconv.HTTPURL(redactURL(req.URL)),
+			semconv.NetPeerName(req.URL.Hostname()),
+		),
+	)
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return ctx
+}
+
+// redactURL removes the credentials from the URL.
+func redactURL(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	redacted := *u
+	redacted.User = nil
+	return redacted.String()
+}
+
+func endSpan(span trace.Span, res *http.Response, err error) {
+	defer span.End()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCode(res.StatusCode))
+	if res.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, res.Status)
+	}
+}
@@ -0,0 +1,22 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+)
+
+type cookiesContextKeyType struct{}
+
+var cookiesContextKey = cookiesContextKeyType{}
+
+func CookiesFromContext(ctx context.Context) []*http.Cookie {
+	cookies, _ := ctx.Value(cookiesContextKey).([]*http.Cookie)
+	return cookies
+}
+
+type ParsedCookiesHook struct{}
+
+func (h *ParsedCookiesHook) Do(res *http.Response) error {
+	setResponseContextValue(res, cookiesContextKey, res.Cookies())
+	return nil
+}
@@ -0,0 +1,42 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParsedCookiesHook(t *testing.T) {
+	t.Run("MultiCookies", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Add("Set-Cookie", "session=abc; Path=/; HttpOnly")
+		res.Header.Add("Set-Cookie", "theme=dark; Max-Age=3600")
+
+		err := (&ParsedCookiesHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cookies := CookiesFromContext(res.Request.Context())
+		if len(cookies) != 2 {
+			t.Fatalf("Cookies should have 2 elements, but got: %#v", cookies)
+		}
+		if c := cookies[0]; c.Name != "session" || c.Value != "abc" || c.Path != "/" || !c.HttpOnly {
+			t.Errorf("Unexpected cookie: %#v", c)
+		}
+		if c := cookies[1]; c.Name != "theme" || c.Value != "dark" || c.MaxAge != 3600 {
+			t.Errorf("Unexpected cookie: %#v", c)
+		}
+	})
+
+	t.Run("NoCookies", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+
+		err := (&ParsedCookiesHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cookies := CookiesFromContext(res.Request.Context()); len(cookies) != 0 {
+			t.Errorf("Cookies should be empty, but got: %#v", cookies)
+		}
+	})
+}
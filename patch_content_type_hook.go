@@ -0,0 +1,38 @@
+package httpagent
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var DefaultPatchContentTypes = []string{
+	"application/json-patch+json",
+	"application/merge-patch+json",
+}
+
+type PatchContentTypeHook struct {
+	AllowedTypes []string
+}
+
+func (h *PatchContentTypeHook) Do(req *http.Request) error {
+	if req.Method != http.MethodPatch {
+		return nil
+	}
+
+	allowedTypes := h.AllowedTypes
+	if allowedTypes == nil {
+		allowedTypes = DefaultPatchContentTypes
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		for _, allowed := range allowedTypes {
+			if strings.EqualFold(allowed, mediaType) {
+				return nil
+			}
+		}
+	}
+	return &ContentTypeError{ContentType: contentType}
+}
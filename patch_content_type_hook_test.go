@@ -0,0 +1,56 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestPatchContentTypeHook(t *testing.T) {
+	hook := &PatchContentTypeHook{}
+
+	t.Run("Valid", func(t *testing.T) {
+		for _, contentType := range []string{"application/json-patch+json", "application/merge-patch+json; charset=utf-8"} {
+			req := mustNewRequest(t, http.MethodPatch, "http://example.com/", nil)
+			req.Header.Set("Content-Type", contentType)
+			err := hook.Do(req)
+			if err != nil {
+				t.Errorf("Unexpected error is occurred for %q: %#v", contentType, err)
+			}
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, contentType := range []string{"application/json", ""} {
+			req := mustNewRequest(t, http.MethodPatch, "http://example.com/", nil)
+			req.Header.Set("Content-Type", contentType)
+			err := hook.Do(req)
+
+			var ctErr *ContentTypeError
+			if !errors.As(err, &ctErr) {
+				t.Errorf("Unexpected error is occurred for %q: %#v", contentType, err)
+			} else if ctErr.ContentType != contentType {
+				t.Errorf("ContentType should be %q, but got: %q", contentType, ctErr.ContentType)
+			}
+		}
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		hook := &PatchContentTypeHook{AllowedTypes: []string{"application/json"}}
+		req := mustNewRequest(t, http.MethodPatch, "http://example.com/", nil)
+		req.Header.Set("Content-Type", "application/json")
+		err := hook.Do(req)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("NotPatch", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", nil)
+		req.Header.Set("Content-Type", "text/plain")
+		err := hook.Do(req)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+}
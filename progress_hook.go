@@ -0,0 +1,68 @@
+package httpagent
+
+import (
+	"io"
+	"net/http"
+)
+
+type progressReadCloser struct {
+	io.ReadCloser
+	n          int64
+	total      int64
+	onProgress func(n, total int64)
+}
+
+func (r *progressReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.n += int64(n)
+		r.onProgress(r.n, r.total)
+	}
+	return n, err
+}
+
+type RequestProgressHook struct {
+	OnProgress func(sent, total int64)
+}
+
+func (h *RequestProgressHook) Do(req *http.Request) error {
+	if h.OnProgress == nil || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	total := req.ContentLength
+	if total <= 0 {
+		total = -1
+	}
+	req.Body = &progressReadCloser{ReadCloser: req.Body, total: total, onProgress: h.OnProgress}
+
+	// keep the body rewindable
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return &progressReadCloser{ReadCloser: body, total: total, onProgress: h.OnProgress}, nil
+		}
+	}
+
+	return nil
+}
+
+type ResponseProgressHook struct {
+	OnProgress func(received, total int64)
+}
+
+func (h *ResponseProgressHook) Do(res *http.Response) error {
+	if h.OnProgress == nil || res.Body == nil || res.Body == http.NoBody {
+		return nil
+	}
+
+	total := res.ContentLength
+	if total < 0 {
+		total = -1
+	}
+	res.Body = &progressReadCloser{ReadCloser: res.Body, total: total, onProgress: h.OnProgress}
+	return nil
+}
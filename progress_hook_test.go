@@ -0,0 +1,152 @@
+package httpagent
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type progress struct {
+	n, total int64
+}
+
+func TestRequestProgressHook(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		var got []progress
+		hook := &RequestProgressHook{OnProgress: func(sent, total int64) {
+			got = append(got, progress{sent, total})
+		}}
+
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("abc"))
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(iotest.OneByteReader(req.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "abc" {
+			t.Errorf("Body should be abc, but got: %s", s)
+		}
+
+		expected := []progress{{1, 3}, {2, 3}, {3, 3}}
+		if diff := cmp.Diff(expected, got, cmp.AllowUnexported(progress{})); diff != "" {
+			t.Errorf("Unexpected progress: %s", diff)
+		}
+	})
+
+	t.Run("Rewind", func(t *testing.T) {
+		var got []progress
+		hook := &RequestProgressHook{OnProgress: func(sent, total int64) {
+			got = append(got, progress{sent, total})
+		}}
+
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("abc"))
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := req.GetBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ioutil.ReadAll(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []progress{{3, 3}}
+		if diff := cmp.Diff(expected, got, cmp.AllowUnexported(progress{})); diff != "" {
+			t.Errorf("Unexpected progress: %s", diff)
+		}
+	})
+
+	t.Run("NoBody", func(t *testing.T) {
+		hook := &RequestProgressHook{OnProgress: func(sent, total int64) {
+			t.Errorf("Should not be called, but called: sent=%d total=%d", sent, total)
+		}}
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Body != nil {
+			t.Errorf("Body should be nil, but got: %#v", req.Body)
+		}
+	})
+
+	t.Run("WithAgent", func(t *testing.T) {
+		ts := setupTestServer(t)
+
+		var last progress
+		agent := NewAgent(http.DefaultClient)
+		agent.RequestHooks.Append(&RequestProgressHook{OnProgress: func(sent, total int64) {
+			last = progress{sent, total}
+		}})
+
+		req := mustNewRequest(t, http.MethodPost, ts.URL, strings.NewReader("hello"))
+		shouldBeOK(t, agent, req, 1)
+		if last != (progress{5, 5}) {
+			t.Errorf("Last progress should be {5, 5}, but got: %#v", last)
+		}
+	})
+}
+
+func TestResponseProgressHook(t *testing.T) {
+	var got []progress
+	hook := &ResponseProgressHook{OnProgress: func(received, total int64) {
+		got = append(got, progress{received, total})
+	}}
+
+	res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+	err := hook.Do(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadAll(iotest.OneByteReader(res.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != "OK" {
+		t.Errorf("Body should be OK, but got: %s", s)
+	}
+
+	expected := []progress{{1, 2}, {2, 2}}
+	if diff := cmp.Diff(expected, got, cmp.AllowUnexported(progress{})); diff != "" {
+		t.Errorf("Unexpected progress: %s", diff)
+	}
+}
+
+func TestProgressHookNilCallback(t *testing.T) {
+	req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("hello"))
+	body := req.Body
+	err := (&RequestProgressHook{}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Body != body {
+		t.Errorf("Request body should not be wrapped without callback, but got: %#v", req.Body)
+	}
+
+	res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+	err = (&ResponseProgressHook{}).Do(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != "OK" {
+		t.Errorf("Body should be OK, but got: %s", s)
+	}
+}
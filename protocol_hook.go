@@ -0,0 +1,47 @@
+package httpagent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type Protocol struct {
+	Proto      string
+	ProtoMajor int
+	ProtoMinor int
+}
+
+type protocolContextKeyType struct{}
+
+var protocolContextKey = protocolContextKeyType{}
+
+func ProtocolFromContext(ctx context.Context) (Protocol, bool) {
+	protocol, ok := ctx.Value(protocolContextKey).(Protocol)
+	return protocol, ok
+}
+
+type ProtocolMismatchError struct {
+	Expected int
+	Actual   Protocol
+}
+
+func (e *ProtocolMismatchError) Error() string {
+	return fmt.Sprintf("httpagent: HTTP/%d is required, but %s is negotiated", e.Expected, e.Actual.Proto)
+}
+
+type ProtocolHook struct {
+	// RequireProtoMajor makes an error if the negotiated major version does not match. (0 means no requirement)
+	RequireProtoMajor int
+}
+
+func (h *ProtocolHook) Do(res *http.Response) error {
+	protocol := Protocol{Proto: res.Proto, ProtoMajor: res.ProtoMajor, ProtoMinor: res.ProtoMinor}
+	setResponseContextValue(res, protocolContextKey, protocol)
+
+	if h.RequireProtoMajor != 0 && h.RequireProtoMajor != res.ProtoMajor {
+		DrainAndClose(res)
+		return &ProtocolMismatchError{Expected: h.RequireProtoMajor, Actual: protocol}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProtocolHook(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	h1 := httptest.NewServer(handler)
+	t.Cleanup(h1.Close)
+
+	h2 := httptest.NewUnstartedServer(handler)
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	t.Cleanup(h2.Close)
+
+	t.Run("HTTP/1.1", func(t *testing.T) {
+		agent := NewAgent(h1.Client())
+		agent.ResponseHooks.Append(&ProtocolHook{})
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, h1.URL, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		protocol, ok := ProtocolFromContext(res.Request.Context())
+		if !ok || protocol != (Protocol{Proto: "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1}) {
+			t.Errorf("Unexpected protocol: %#v", protocol)
+		}
+	})
+
+	t.Run("HTTP/2", func(t *testing.T) {
+		agent := NewAgent(h2.Client())
+		agent.ResponseHooks.Append(&ProtocolHook{RequireProtoMajor: 2})
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, h2.URL, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		protocol, ok := ProtocolFromContext(res.Request.Context())
+		if !ok || protocol != (Protocol{Proto: "HTTP/2.0", ProtoMajor: 2, ProtoMinor: 0}) {
+			t.Errorf("Unexpected protocol: %#v", protocol)
+		}
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		agent := NewAgent(h1.Client())
+		agent.ResponseHooks.Append(&ProtocolHook{RequireProtoMajor: 2})
+
+		_, err := agent.Do(mustNewRequest(t, http.MethodGet, h1.URL, nil))
+		var mismatch *ProtocolMismatchError
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if mismatch.Expected != 2 || mismatch.Actual.ProtoMajor != 1 {
+			t.Errorf("Unexpected error: %#v", mismatch)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("httpagent: circuit is open")
+
+const (
+	DefaultCircuitBreakerCooldown   = 30 * time.Second
+	DefaultCircuitBreakerWindowSize = 20
+)
+
+// RateCircuitBreakerClient trips when the failure ratio over the last WindowSize requests exceeds Threshold.
+// While tripped, requests fail with ErrCircuitOpen for Cooldown, and then it starts over with an empty window.
+type RateCircuitBreakerClient struct {
+	Client    Client
+	Threshold float64
+	Cooldown  time.Duration
+	Clock     Clock
+
+	// IsFailure reports whether the outcome is a failure. (default: error or 5xx)
+	IsFailure func(*http.Response, error) bool
+
+	mu        sync.Mutex
+	outcomes  []bool
+	pos       int
+	count     int
+	failures  int
+	openUntil time.Time
+}
+
+var _ Client = &RateCircuitBreakerClient{}
+
+func NewRateCircuitBreakerClient(client Client, windowSize int, threshold float64) *RateCircuitBreakerClient {
+	if windowSize <= 0 {
+		panic("non-positive circuit breaker window size")
+	}
+	return &RateCircuitBreakerClient{
+		Client:    client,
+		Threshold: threshold,
+		outcomes:  make([]bool, windowSize),
+	}
+}
+
+func (c *RateCircuitBreakerClient) Do(req *http.Request) (*http.Response, error) {
+	if c.isOpen() {
+		return nil, ErrCircuitOpen
+	}
+
+	res, err := c.Client.Do(req)
+
+	isFailure := c.IsFailure
+	if isFailure == nil {
+		isFailure = DefaultFallbackOn
+	}
+	c.record(isFailure(res, err))
+	return res, err
+}
+
+func (c *RateCircuitBreakerClient) clock() Clock {
+	if c.Clock == nil {
+		return RealClock
+	}
+	return c.Clock
+}
+
+func (c *RateCircuitBreakerClient) isOpen() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clock().Now().Before(c.openUntil)
+}
+
+func (c *RateCircuitBreakerClient) record(failure bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the zero value uses the default window size
+	if c.outcomes == nil {
+		c.outcomes = make([]bool, DefaultCircuitBreakerWindowSize)
+	}
+
+	// ring buffer of the outcomes
+	if c.count == len(c.outcomes) {
+		if c.outcomes[c.pos] {
+			c.failures--
+		}
+	} else {
+		c.count++
+	}
+	c.outcomes[c.pos] = failure
+	if failure {
+		c.failures++
+	}
+	c.pos = (c.pos + 1) % len(c.outcomes)
+
+	if c.count < len(c.outcomes) || float64(c.failures)/float64(c.count) <= c.Threshold {
+		return
+	}
+
+	cooldown := c.Cooldown
+	if cooldown == 0 {
+		cooldown = DefaultCircuitBreakerCooldown
+	}
+	c.openUntil = c.clock().Now().Add(cooldown)
+	c.pos, c.count, c.failures = 0, 0, 0
+}
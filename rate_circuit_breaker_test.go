@@ -0,0 +1,92 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestRateCircuitBreakerClient(t *testing.T) {
+	var calls int
+	var statuses []int
+	client := NewRateCircuitBreakerClient(ClientFunc(func(req *http.Request) (*http.Response, error) {
+		status := statuses[calls]
+		calls++
+		return mockhttp.NewResponseMock(status, nil, nil).MakeResponse(req), nil
+	}), 4, 0.5)
+	clock := newFakeClock()
+	client.Clock = clock
+	client.Cooldown = time.Minute
+
+	do := func(t *testing.T) error {
+		_, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		return err
+	}
+
+	// S, F, S, F is 50% and not exceeding the threshold, and then F makes it 75%
+	statuses = []int{200, 500, 200, 500, 503, 200, 200}
+	for i := 0; i < 5; i++ {
+		if err := do(t); err != nil {
+			t.Fatalf("Unexpected error is occurred at %d: %#v", i, err)
+		}
+	}
+
+	if err := do(t); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Circuit should be open, but got: %#v", err)
+	}
+	if calls != 5 {
+		t.Errorf("Client should not be called while open, but called %d times", calls)
+	}
+
+	clock.Advance(time.Minute)
+	if err := do(t); err != nil {
+		t.Errorf("Circuit should be closed after cooldown, but got: %#v", err)
+	}
+	if calls != 6 {
+		t.Errorf("Client should be called after cooldown, but called %d times", calls)
+	}
+
+	t.Run("ErrorOutcome", func(t *testing.T) {
+		expectedErr := errors.New("connection refused")
+		client := NewRateCircuitBreakerClient(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, expectedErr
+		}), 2, 0.5)
+
+		for i := 0; i < 2; i++ {
+			if _, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil)); err != expectedErr {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+		}
+		if _, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil)); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Circuit should be open, but got: %#v", err)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("The code did not panic")
+			}
+		}()
+		NewRateCircuitBreakerClient(http.DefaultClient, 0, 0.5)
+	})
+}
+
+func TestRateCircuitBreakerClientZeroValue(t *testing.T) {
+	client := &RateCircuitBreakerClient{
+		Client: mockhttp.NewResponseMock(http.StatusInternalServerError, nil, nil).MakeClient(),
+		Clock:  newFakeClock(),
+	}
+
+	for i := 0; i < DefaultCircuitBreakerWindowSize; i++ {
+		if _, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil)); err != nil {
+			t.Fatalf("Unexpected error is occurred at %d: %#v", i, err)
+		}
+	}
+	if _, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil)); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Circuit should be open after the default window, but got: %#v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package httpagent
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+type RateLimitHook struct {
+	Limiter *rate.Limiter
+}
+
+func NewRateLimitHook(r rate.Limit, b int) *RateLimitHook {
+	return &RateLimitHook{Limiter: rate.NewLimiter(r, b)}
+}
+
+func (h *RateLimitHook) Do(req *http.Request) error {
+	return h.Limiter.Wait(req.Context())
+}
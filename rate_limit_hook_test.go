@@ -0,0 +1,46 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitHook(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		agent := NewAgent(mockhttp.NewResponseMock(http.StatusOK, nil, []byte("OK")).MakeClient())
+		agent.RequestHooks.Append(NewRateLimitHook(rate.Every(50*time.Millisecond), 1))
+
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+			if err != nil {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			res.Body.Close()
+		}
+
+		// the first request consumes the burst, and the rest wait 50ms for each
+		if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+			t.Errorf("Elapsed time should be 200ms at least, but got: %s", elapsed)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		hook := NewRateLimitHook(rate.Every(time.Hour), 1)
+		if err := hook.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil)); err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil).WithContext(ctx)
+		if err := hook.Do(req); err == nil {
+			t.Error("Should be error")
+		}
+	})
+}
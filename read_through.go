@@ -0,0 +1,165 @@
+package httpagent
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReadThrough coalesces concurrent identical GET requests and caches successful responses for TTL.
+// The requests are identified as finally sent by the client of Agent, after the default headers, the context headers and the request hooks are applied.
+// Each caller receives an independent copy of the response body, and the response hooks of Agent run for each caller.
+// Responses with Vary are shared by the coalesced requests, but never cached.
+// Requests with a client set by ContextWithClient are never coalesced nor cached.
+type ReadThrough struct {
+	Agent *Agent
+	TTL   time.Duration
+
+	// Key identifies the identical requests. (nil means DefaultReadThroughKey)
+	Key func(req *http.Request) string
+
+	mu      sync.Mutex
+	calls   map[string]*readThroughCall
+	entries map[string]*readThroughEntry
+}
+
+var _ Client = &ReadThrough{}
+
+func NewReadThrough(agent *Agent, ttl time.Duration) *ReadThrough {
+	return &ReadThrough{Agent: agent, TTL: ttl}
+}
+
+type readThroughCall struct {
+	wg    sync.WaitGroup
+	entry *readThroughEntry
+	err   error
+}
+
+type readThroughEntry struct {
+	res     *http.Response
+	body    []byte
+	expires time.Time
+}
+
+func (e *readThroughEntry) response(req *http.Request) *http.Response {
+	res := *e.res
+	res.Header = e.res.Header.Clone()
+	res.Body = io.NopCloser(bytes.NewReader(e.body))
+	res.ContentLength = int64(len(e.body))
+	res.Request = req
+	return &res
+}
+
+func (r *ReadThrough) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || contextClient(req.Context()) != nil || r.Agent.Client == nil {
+		return r.Agent.Do(req)
+	}
+
+	// intercept the request as finally sent to identify it
+	client := &readThroughClient{ReadThrough: r, Client: r.Agent.Client}
+	return r.Agent.Do(req.WithContext(ContextWithClient(req.Context(), client)))
+}
+
+type readThroughClient struct {
+	*ReadThrough
+	Client Client
+}
+
+func (c *readThroughClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return c.Client.Do(req)
+	}
+	return c.do(c.Client, req)
+}
+
+func (r *ReadThrough) do(client Client, req *http.Request) (*http.Response, error) {
+	keyFunc := r.Key
+	if keyFunc == nil {
+		keyFunc = DefaultReadThroughKey
+	}
+	key := keyFunc(req)
+	clock := r.Agent.clock()
+
+	for {
+		r.mu.Lock()
+		if entry, ok := r.entries[key]; ok {
+			if clock.Now().Before(entry.expires) {
+				r.mu.Unlock()
+				return entry.response(req), nil
+			}
+			delete(r.entries, key)
+		}
+		call, ok := r.calls[key]
+		if !ok {
+			break
+		}
+		r.mu.Unlock()
+
+		call.wg.Wait()
+		if call.err == nil {
+			return call.entry.response(req), nil
+		}
+
+		// re-issue the request if the leader has given up by its own context
+		if isContextError(call.err) && req.Context().Err() == nil {
+			continue
+		}
+		return nil, call.err
+	}
+
+	if r.calls == nil {
+		r.calls = map[string]*readThroughCall{}
+	}
+	call := &readThroughCall{}
+	call.wg.Add(1)
+	r.calls[key] = call
+	r.mu.Unlock()
+
+	call.entry, call.err = fetchReadThroughEntry(client, req)
+
+	r.mu.Lock()
+	delete(r.calls, key)
+	if call.err == nil && isSuccessStatus(call.entry.res.StatusCode) && call.entry.res.Header.Get("Vary") == "" {
+		if r.entries == nil {
+			r.entries = map[string]*readThroughEntry{}
+		}
+		call.entry.expires = clock.Now().Add(r.TTL)
+		r.entries[key] = call.entry
+	}
+	r.mu.Unlock()
+	call.wg.Done()
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	return call.entry.response(req), nil
+}
+
+// DefaultReadThroughKey identifies the requests by the method, the URL and the credentials not to share responses between users.
+func DefaultReadThroughKey(req *http.Request) string {
+	return strings.Join([]string{
+		req.Method,
+		req.URL.String(),
+		strings.Join(req.Header.Values("Authorization"), ","),
+		strings.Join(req.Header.Values("Cookie"), "; "),
+	}, "\n")
+}
+
+func fetchReadThroughEntry(client Client, req *http.Request) (*readThroughEntry, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = nil
+	return &readThroughEntry{res: res, body: body}, nil
+}
@@ -0,0 +1,210 @@
+package httpagent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReadThrough(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Header().Set("Foo", "Bar")
+		_, _ = io.WriteString(w, "OK")
+	}))
+	t.Cleanup(ts.Close)
+
+	clock := newFakeClock()
+	agent := NewAgent(http.DefaultClient)
+	agent.Clock = clock
+	rt := NewReadThrough(agent, time.Minute)
+
+	fetch := func(t *testing.T) {
+		res, err := rt.Do(mustNewRequest(t, http.MethodGet, ts.URL, nil))
+		if err != nil {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+			return
+		}
+		defer res.Body.Close()
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if s := string(b); s != "OK" {
+			t.Errorf("Body should be OK, but got: %s", s)
+		}
+		if foo := res.Header.Get("Foo"); foo != "Bar" {
+			t.Errorf("Foo header should be Bar, but got: %s", foo)
+		}
+	}
+
+	t.Run("Coalesce", func(t *testing.T) {
+		const n = 10
+
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				fetch(t)
+			}()
+		}
+
+		// late callers are served from the cache
+		waitUntil(t, func() bool {
+			return atomic.LoadInt32(&calls) == 1
+		})
+		close(release)
+		wg.Wait()
+
+		if c := atomic.LoadInt32(&calls); c != 1 {
+			t.Errorf("Underlying calls should be 1, but got: %d", c)
+		}
+	})
+
+	t.Run("CacheHit", func(t *testing.T) {
+		fetch(t)
+		fetch(t)
+		if c := atomic.LoadInt32(&calls); c != 1 {
+			t.Errorf("Underlying calls should be 1, but got: %d", c)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		clock.Advance(time.Minute)
+		fetch(t)
+		if c := atomic.LoadInt32(&calls); c != 2 {
+			t.Errorf("Underlying calls should be 2, but got: %d", c)
+		}
+	})
+
+	t.Run("NotGET", func(t *testing.T) {
+		res, err := rt.Do(mustNewRequest(t, http.MethodPost, ts.URL, nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+		if c := atomic.LoadInt32(&calls); c != 3 {
+			t.Errorf("Underlying calls should be 3, but got: %d", c)
+		}
+	})
+	t.Run("Credentials", func(t *testing.T) {
+		for _, user := range []string{"alice", "bob"} {
+			req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+			req.SetBasicAuth(user, "password")
+			res, err := rt.Do(req)
+			if err != nil {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			res.Body.Close()
+		}
+		if c := atomic.LoadInt32(&calls); c != 5 {
+			t.Errorf("Responses should not be shared between users, but underlying calls: %d", c)
+		}
+	})
+}
+
+func TestReadThroughVary(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Vary", "Accept-Language")
+		_, _ = io.WriteString(w, "OK")
+	}))
+	t.Cleanup(ts.Close)
+
+	rt := NewReadThrough(NewAgent(http.DefaultClient), time.Minute)
+	rt.Key = func(req *http.Request) string {
+		return req.URL.Path
+	}
+	for i := 0; i < 2; i++ {
+		res, err := rt.Do(mustNewRequest(t, http.MethodGet, ts.URL+"/path", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+	}
+	if c := atomic.LoadInt32(&calls); c != 2 {
+		t.Errorf("Responses with Vary should not be cached, but underlying calls: %d", c)
+	}
+}
+
+func TestReadThroughFinalRequest(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = io.WriteString(w, r.Header.Get("Authorization"))
+	}))
+	t.Cleanup(ts.Close)
+
+	// the zero value works without NewReadThrough
+	rt := &ReadThrough{Agent: NewAgent(http.DefaultClient), TTL: time.Minute}
+
+	for _, user := range []string{"alice", "bob", "alice"} {
+		req := mustNewRequest(t, http.MethodGet, ts.URL, nil)
+		ctx := ContextWithHeader(req.Context(), http.Header{"Authorization": {"Bearer " + user}})
+		res, err := rt.Do(req.WithContext(ctx))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		b, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if s := string(b); s != "Bearer "+user {
+			t.Errorf("Response should be for %s, but got: %s", user, s)
+		}
+	}
+	if c := atomic.LoadInt32(&calls); c != 2 {
+		t.Errorf("Responses should be cached for each user, but underlying calls: %d", c)
+	}
+}
+
+func TestReadThroughLeaderCanceled(t *testing.T) {
+	var calls int32
+	started := make(chan struct{})
+	agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("OK")), Request: req}, nil
+	}))
+	rt := NewReadThrough(agent, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		_, err := rt.Do(req.WithContext(ctx))
+		leader <- err
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		res, err := rt.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err == nil {
+			res.Body.Close()
+		}
+		waiter <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-leader; err != context.Canceled {
+		t.Errorf("Leader should be canceled, but got: %#v", err)
+	}
+	if err := <-waiter; err != nil {
+		t.Errorf("Waiter should re-issue the request, but got: %#v", err)
+	}
+}
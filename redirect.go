@@ -0,0 +1,150 @@
+package httpagent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const DefaultMaxRedirects = 10
+
+var (
+	ErrTooManyRedirects = errors.New("httpagent: too many redirects")
+	ErrRedirectBlocked  = errors.New("httpagent: redirect is blocked")
+)
+
+// RedirectPolicy configures agent-level redirect following.
+// The client should not follow redirects by itself (e.g. http.Client with CheckRedirect returning http.ErrUseLastResponse).
+type RedirectPolicy struct {
+	MaxRedirects int
+
+	// PreservePOSTOn301 and PreservePOSTOn302 keep the method and the body on the status as RFC 7231 allows.
+	// By default, the method other than GET and HEAD is changed to GET like net/http.
+	PreservePOSTOn301 bool
+	PreservePOSTOn302 bool
+
+	// AllowedHosts restricts the redirect targets if not empty.
+	// A host may have a port (e.g. "example.com:8080") or a leading wildcard label (e.g. "*.example.com").
+	AllowedHosts []string
+}
+
+func (p *RedirectPolicy) RedirectMethod(statusCode int, method string) (redirectMethod string, includeBody bool) {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther:
+		if statusCode == http.StatusMovedPermanently && p.PreservePOSTOn301 || statusCode == http.StatusFound && p.PreservePOSTOn302 {
+			return method, true
+		}
+		if method != http.MethodGet && method != http.MethodHead {
+			return http.MethodGet, false
+		}
+		return method, false
+	case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return method, true
+	default:
+		return "", false
+	}
+}
+
+func (p *RedirectPolicy) follow(client Client, req *http.Request, res *http.Response) (*http.Response, error) {
+	maxRedirects := p.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+
+	for redirects := 0; ; redirects++ {
+		next, err := p.nextRequest(req, res)
+		if err != nil {
+			DrainAndClose(res)
+			return nil, err
+		}
+		if next == nil {
+			return res, nil
+		}
+		if redirects >= maxRedirects {
+			DrainAndClose(res)
+			return nil, ErrTooManyRedirects
+		}
+		DrainAndClose(res)
+
+		req = next
+		res, err = client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *RedirectPolicy) nextRequest(req *http.Request, res *http.Response) (*http.Request, error) {
+	method, includeBody := p.RedirectMethod(res.StatusCode, req.Method)
+	if method == "" {
+		return nil, nil
+	}
+
+	location := res.Header.Get("Location")
+	if location == "" {
+		return nil, nil
+	}
+	u, err := req.URL.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.AllowedHosts) != 0 && !matchHosts(p.AllowedHosts, u.Host) {
+		return nil, fmt.Errorf("%w: %s", ErrRedirectBlocked, u.Host)
+	}
+
+	next := req.Clone(req.Context())
+	next.Method = method
+	next.URL = u
+	next.Host = ""
+	if includeBody && req.Body != nil && req.Body != http.NoBody {
+		// cannot rewind the body
+		if req.GetBody == nil {
+			return nil, nil
+		}
+		next.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	} else if !includeBody {
+		next.Body = nil
+		next.GetBody = nil
+		next.ContentLength = 0
+		next.Header.Del("Content-Type")
+		next.Header.Del("Content-Length")
+	}
+
+	// do not leak credentials to another host
+	if u.Host != req.URL.Host {
+		next.Header.Del("Authorization")
+		next.Header.Del("Proxy-Authorization")
+		next.Header.Del("Www-Authenticate")
+		next.Header.Del("Cookie")
+	}
+	return next, nil
+}
+
+func matchHosts(patterns []string, host string) bool {
+	host = strings.ToLower(host)
+	hostname := host
+	if i := strings.LastIndexByte(host, ':'); i != -1 && !strings.HasSuffix(host, "]") {
+		hostname = host[:i]
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+
+		target := hostname
+		if strings.Contains(pattern, ":") {
+			target = host
+		}
+
+		if pattern == target {
+			return true
+		}
+		if strings.HasPrefix(pattern, "*.") && strings.HasSuffix(target, pattern[1:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package httpagent
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var noRedirectClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func setupRedirectTestServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/loop" {
+			http.Redirect(w, r, "/loop", http.StatusFound)
+			return
+		}
+		if status, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/")); err == nil {
+			http.Redirect(w, r, "/echo", status)
+			return
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.URL.Path, b)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	ts := setupRedirectTestServer(t)
+
+	cases := []struct {
+		status   int
+		policy   RedirectPolicy
+		expected string
+	}{
+		{status: 301, expected: "GET /echo "},
+		{status: 302, expected: "GET /echo "},
+		{status: 303, expected: "GET /echo "},
+		{status: 307, expected: "POST /echo body"},
+		{status: 308, expected: "POST /echo body"},
+		{status: 301, policy: RedirectPolicy{PreservePOSTOn301: true, PreservePOSTOn302: true}, expected: "POST /echo body"},
+		{status: 302, policy: RedirectPolicy{PreservePOSTOn301: true, PreservePOSTOn302: true}, expected: "POST /echo body"},
+		{status: 303, policy: RedirectPolicy{PreservePOSTOn301: true, PreservePOSTOn302: true}, expected: "GET /echo "},
+		{status: 307, policy: RedirectPolicy{PreservePOSTOn301: true, PreservePOSTOn302: true}, expected: "POST /echo body"},
+		{status: 308, policy: RedirectPolicy{PreservePOSTOn301: true, PreservePOSTOn302: true}, expected: "POST /echo body"},
+	}
+	for _, tc := range cases {
+		tc := tc
+		name := fmt.Sprintf("%d/Preserve=%v", tc.status, tc.policy.PreservePOSTOn301)
+		t.Run(name, func(t *testing.T) {
+			agent := NewAgent(noRedirectClient)
+			agent.RedirectPolicy = &tc.policy
+
+			req := mustNewRequest(t, http.MethodPost, ts.URL+"/"+strconv.Itoa(tc.status), strings.NewReader("body"))
+			res, err := agent.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			b, err := ioutil.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := string(b); s != tc.expected {
+				t.Errorf("Response should be %q, but got: %q", tc.expected, s)
+			}
+		})
+	}
+
+	t.Run("Head", func(t *testing.T) {
+		for _, status := range []int{301, 302, 303, 307, 308} {
+			if method, _ := (&RedirectPolicy{}).RedirectMethod(status, http.MethodHead); method != http.MethodHead {
+				t.Errorf("HEAD should be preserved on %d, but got: %s", status, method)
+			}
+		}
+	})
+
+	t.Run("TooManyRedirects", func(t *testing.T) {
+		agent := NewAgent(noRedirectClient)
+		agent.RedirectPolicy = &RedirectPolicy{MaxRedirects: 3}
+
+		req := mustNewRequest(t, http.MethodGet, ts.URL+"/loop", nil)
+		_, err := agent.Do(req)
+		if !errors.Is(err, ErrTooManyRedirects) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("AllowedHosts", func(t *testing.T) {
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, ts.URL+"/echo", http.StatusFound)
+		}))
+		t.Cleanup(other.Close)
+
+		// both servers listen on 127.0.0.1 with different ports
+		u, err := url.Parse(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run("Allowed", func(t *testing.T) {
+			agent := NewAgent(noRedirectClient)
+			agent.RedirectPolicy = &RedirectPolicy{AllowedHosts: []string{u.Host}}
+
+			res, err := agent.Do(mustNewRequest(t, http.MethodGet, other.URL, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("Redirect should be followed, but got: %d", res.StatusCode)
+			}
+		})
+
+		t.Run("Blocked", func(t *testing.T) {
+			agent := NewAgent(noRedirectClient)
+			agent.RedirectPolicy = &RedirectPolicy{AllowedHosts: []string{"example.com"}}
+
+			res, err := agent.Do(mustNewRequest(t, http.MethodGet, other.URL, nil))
+			if !errors.Is(err, ErrRedirectBlocked) {
+				t.Errorf("Unexpected error is occurred: %#v", err)
+			}
+			if res != nil {
+				t.Errorf("Should be no response, but got: %#v", res)
+			}
+		})
+	})
+
+	t.Run("CrossHostCredentials", func(t *testing.T) {
+		var received http.Header
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r.Header.Clone()
+		}))
+		t.Cleanup(target.Close)
+		origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, target.URL+"/", http.StatusFound)
+		}))
+		t.Cleanup(origin.Close)
+
+		agent := NewAgent(noRedirectClient)
+		agent.RedirectPolicy = &RedirectPolicy{}
+
+		req := mustNewRequest(t, http.MethodGet, origin.URL+"/", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("Proxy-Authorization", "Basic secret")
+		req.Header.Set("Cookie", "session=secret")
+		res, err := agent.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		for _, key := range []string{"Authorization", "Proxy-Authorization", "Cookie"} {
+			if v := received.Get(key); v != "" {
+				t.Errorf("%s should not be sent to another host, but got: %q", key, v)
+			}
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		agent := NewAgent(noRedirectClient)
+
+		req := mustNewRequest(t, http.MethodGet, ts.URL+"/302", nil)
+		res, err := agent.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusFound {
+			t.Errorf("Redirect should not be followed, but got: %d", res.StatusCode)
+		}
+	})
+}
+
+func TestMatchHosts(t *testing.T) {
+	patterns := []string{"example.com", "*.example.net", "localhost:8080"}
+	cases := map[string]bool{
+		"example.com":         true,
+		"EXAMPLE.com:443":     true,
+		"www.example.com":     false,
+		"api.example.net":     true,
+		"example.net":         false,
+		"localhost:8080":      true,
+		"localhost:8081":      false,
+		"localhost":           false,
+		"evil-example.com":    false,
+		"example.com.evil":    false,
+		"[::1]":               false,
+		"sub.api.example.net": true,
+	}
+	for host, expected := range cases {
+		if actual := matchHosts(patterns, host); actual != expected {
+			t.Errorf("matchHosts(%q) should be %v, but got: %v", host, expected, actual)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package httpagent
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
+	"reflect"
+	"sync"
 )
 
 type RequestHook interface {
@@ -24,7 +28,10 @@ func (h nopRequestHook) Do(_ *http.Request) error {
 	return nil
 }
 
+// RequestHooks runs the hooks in order.
+// It is safe to modify the hooks while Do is running, and Do runs the hooks as of its start.
 type RequestHooks struct {
+	mu    sync.RWMutex
 	hooks []RequestHook
 }
 
@@ -47,16 +54,101 @@ func (h *RequestHooks) Append(hook RequestHook) {
 	}
 
 	// Optimize: flatten
+	appended := []RequestHook{hook}
 	if hooks, ok := hook.(*RequestHooks); ok {
-		h.hooks = append(h.hooks, hooks.hooks...)
+		appended = hooks.snapshot()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, appended...)
+}
+
+func (h *RequestHooks) Prepend(hook RequestHook) {
+	h.InsertAt(0, hook)
+}
+
+func (h *RequestHooks) InsertAt(i int, hook RequestHook) {
+	if hook == nil {
+		panic("nil hook")
+	}
+
+	// Optimize: flatten
+	inserted := []RequestHook{hook}
+	if hooks, ok := hook.(*RequestHooks); ok {
+		inserted = hooks.snapshot()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i < 0 || i > len(h.hooks) {
+		panic(fmt.Sprintf("hook index out of range [%d] with length %d", i, len(h.hooks)))
+	}
+
+	// Optimize: skip to add nop
+	if hook == NopRequestHook {
 		return
 	}
 
-	h.hooks = append(h.hooks, hook)
+	// build a new slice not to share the backing array with the inserted hooks
+	hooks := make([]RequestHook, 0, len(h.hooks)+len(inserted))
+	hooks = append(hooks, h.hooks[:i]...)
+	hooks = append(hooks, inserted...)
+	hooks = append(hooks, h.hooks[i:]...)
+	h.hooks = hooks
+}
+
+func (h *RequestHooks) RemoveAt(i int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeAt(i)
+}
+
+func (h *RequestHooks) removeAt(i int) {
+	if i < 0 || i >= len(h.hooks) {
+		panic(fmt.Sprintf("hook index out of range [%d] with length %d", i, len(h.hooks)))
+	}
+
+	// build a new slice not to modify the hooks running by Do
+	hooks := make([]RequestHook, 0, len(h.hooks)-1)
+	hooks = append(hooks, h.hooks[:i]...)
+	hooks = append(hooks, h.hooks[i+1:]...)
+	h.hooks = hooks
+}
+
+// Remove removes the first hook equal to the given one, and reports whether it is removed.
+// Hooks of non-comparable types such as RequestHookFunc are never removed.
+func (h *RequestHooks) Remove(hook RequestHook) bool {
+	if hook == nil || !reflect.TypeOf(hook).Comparable() {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, v := range h.hooks {
+		if v == hook {
+			h.removeAt(i)
+			return true
+		}
+	}
+	return false
+}
+
+func (h *RequestHooks) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = nil
+}
+
+// snapshot returns the current hooks. The returned slice must not be modified.
+func (h *RequestHooks) snapshot() []RequestHook {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.hooks[:len(h.hooks):len(h.hooks)]
 }
 
 func (h *RequestHooks) Do(req *http.Request) (err error) {
-	for _, hook := range h.hooks {
+	for _, hook := range h.snapshot() {
 		err = hook.Do(req)
 		if err != nil {
 			return
@@ -66,21 +158,48 @@ func (h *RequestHooks) Do(req *http.Request) (err error) {
 }
 
 func (h *RequestHooks) Len() int {
-	return len(h.hooks)
+	return len(h.snapshot())
+}
+
+// Hooks returns a copy of the hooks in the order they run.
+func (h *RequestHooks) Hooks() []RequestHook {
+	current := h.snapshot()
+	hooks := make([]RequestHook, len(current))
+	copy(hooks, current)
+	return hooks
 }
 
 func (h *RequestHooks) Clone() *RequestHooks {
-	hooks := make([]RequestHook, len(h.hooks))
-	copy(hooks, h.hooks)
-	return &RequestHooks{hooks: hooks}
+	if h == nil {
+		return nil
+	}
+
+	return &RequestHooks{hooks: h.Hooks()}
 }
 
+// DefaultRedactHeaders are the headers masked by the dumper hooks by default.
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
 type RequestDumperHook struct {
 	Writer io.Writer
+
+	// RedactHeaders masks the values of the headers in the dump. (nil means DefaultRedactHeaders, and empty means nothing)
+	RedactHeaders []string
+
+	// SkipBody dumps the headers only.
+	// It is inverted from a Body option so that the zero value keeps dumping the body as before.
+	SkipBody bool
 }
 
 func (h *RequestDumperHook) Do(req *http.Request) error {
-	dump, err := httputil.DumpRequestOut(req, true)
+	// dump a clone to keep the real headers intact
+	clone := req.Clone(req.Context())
+	clone.Header = redactHeader(req.Header, redactHeaders(h.RedactHeaders))
+
+	dump, err := httputil.DumpRequestOut(clone, !h.SkipBody)
+
+	// the dump has drained and restored the shared body
+	req.Body = clone.Body
 	if err != nil {
 		return err
 	}
@@ -88,7 +207,7 @@ func (h *RequestDumperHook) Do(req *http.Request) error {
 
 	var n, wrote int
 	for wrote < len(dump) {
-		n, err = h.Writer.Write(dump)
+		n, err = h.Writer.Write(dump[wrote:])
 		if err != nil {
 			return err
 		}
@@ -122,3 +241,15 @@ func (h *RequestHeaderHook) Do(req *http.Request) error {
 
 	return nil
 }
+
+func redactHeaders(keys []string) []string {
+	if keys == nil {
+		return DefaultRedactHeaders
+	}
+	return keys
+}
+
+// setRequestContextValue replaces the context of the request in place, so that the caller of the hook can see the value.
+func setRequestContextValue(req *http.Request, key, value interface{}) {
+	*req = *req.WithContext(context.WithValue(req.Context(), key, value))
+}
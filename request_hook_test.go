@@ -3,6 +3,7 @@ package httpagent
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"net/http"
 	"net/textproto"
 	"net/url"
@@ -141,16 +142,303 @@ func TestRequestHooks(t *testing.T) {
 	})
 }
 
-func TestRequestDumperHook(t *testing.T) {
-	buf := &bytes.Buffer{}
-	err := (&RequestDumperHook{Writer: buf}).Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
-	if err != nil {
-		t.Error(err)
+func TestRequestHooksInsert(t *testing.T) {
+	var order []string
+	hook := func(name string) RequestHook {
+		return RequestHookFunc(func(_ *http.Request) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	t.Run("Order", func(t *testing.T) {
+		order = nil
+		hooks := NewRequestHooks(hook("b"))
+		hooks.Append(hook("d"))
+		hooks.Prepend(hook("a"))
+		hooks.InsertAt(2, hook("c"))
+		hooks.InsertAt(hooks.Len(), hook("f"))
+		hooks.InsertAt(4, NewRequestHooks(hook("e1"), NopRequestHook, hook("e2")))
+		hooks.Prepend(NopRequestHook)
+		if hooks.Len() != 7 {
+			t.Errorf("Should flatten hooks and skip nop, but got: %#v", hooks)
+		}
+
+		err := hooks.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"a", "b", "c", "d", "e1", "e2", "f"}
+		if diff := cmp.Diff(expected, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+	})
+
+	t.Run("NotShareBackingArray", func(t *testing.T) {
+		order = nil
+		inner := NewRequestHooks(hook("x"), hook("y"))
+		hooks := NewRequestHooks(hook("a"))
+		hooks.Prepend(inner)
+		inner.Append(hook("z"))
+
+		err := hooks.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"x", "y", "a"}
+		if diff := cmp.Diff(expected, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+	})
+
+	for _, i := range []int{-1, 2} {
+		i := i
+		t.Run(fmt.Sprintf("Panic/%d", i), func(t *testing.T) {
+			hooks := NewRequestHooks(hook("a"))
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("The code did not panic")
+				}
+			}()
+			hooks.InsertAt(i, hook("b"))
+		})
+	}
+
+	t.Run("PanicNil", func(t *testing.T) {
+		hooks := NewRequestHooks()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("The code did not panic")
+			}
+		}()
+		hooks.Prepend(nil)
+	})
+}
+
+func TestRequestHooksRemove(t *testing.T) {
+	var order []string
+	hook := func(name string) RequestHook {
+		return RequestHookFunc(func(_ *http.Request) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	t.Run("RemoveAt", func(t *testing.T) {
+		order = nil
+		hooks := NewRequestHooks(hook("a"), hook("b"), hook("c"), hook("d"))
+		hooks.RemoveAt(1)
+		hooks.RemoveAt(hooks.Len() - 1)
+
+		err := hooks.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"a", "c"}, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		order = nil
+		target := &RequestHeaderHook{}
+		hooks := NewRequestHooks(hook("a"), target, hook("b"), target)
+
+		if !hooks.Remove(target) {
+			t.Error("Remove should return true")
+		}
+		if hooks.Len() != 3 {
+			t.Errorf("Only the first match should be removed, but got: %#v", hooks)
+		}
+		if !hooks.Remove(target) || hooks.Remove(target) {
+			t.Error("Remove should return true only while the hook remains")
+		}
+		if hooks.Remove(&RequestHeaderHook{}) {
+			t.Error("Remove should not remove another pointer")
+		}
+		if hooks.Remove(hook("a")) {
+			t.Error("Remove should return false for a non-comparable hook")
+		}
+
+		err := hooks.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"a", "b"}, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		hooks := NewRequestHooks(hook("a"), hook("b"))
+		hooks.Clear()
+		if hooks.Len() != 0 {
+			t.Errorf("Should be empty, but got: %#v", hooks)
+		}
+	})
+
+	for _, i := range []int{-1, 1} {
+		i := i
+		t.Run(fmt.Sprintf("Panic/%d", i), func(t *testing.T) {
+			hooks := NewRequestHooks(hook("a"))
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("The code did not panic")
+				}
+			}()
+			hooks.RemoveAt(i)
+		})
+	}
+}
+
+func TestRequestHooksHooks(t *testing.T) {
+	a, b := &RequestHeaderHook{}, &RequestHeaderHook{}
+	hooks := NewRequestHooks(a, b)
+
+	got := hooks.Hooks()
+	if len(got) != 2 || got[0] != a || got[1] != b {
+		t.Fatalf("Hooks should be [a b], but got: %#v", got)
+	}
+
+	got[0], got[1] = NopRequestHook, NopRequestHook
+	if list := hooks.Hooks(); list[0] != a || list[1] != b {
+		t.Errorf("Mutating the returned slice should not affect the hooks, but got: %#v", list)
 	}
+}
+
+func TestRequestHooksConcurrent(t *testing.T) {
+	hooks := NewRequestHooks()
+	hook := RequestHookFunc(func(req *http.Request) error { return nil })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			hooks.Append(hook)
+			hooks.InsertAt(0, NewRequestHooks(hook))
+			hooks.RemoveAt(hooks.Len() - 1)
+			if i%10 == 0 {
+				hooks.Clear()
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if err := hooks.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
+
+func TestRequestDumperHook(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := (&RequestDumperHook{Writer: buf}).Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dump := buf.String(); !strings.HasPrefix(dump, "GET /") {
+			t.Errorf("Unexpected dump: %s", dump)
+		}
+	})
+
+	t.Run("RedactHeaders", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			redact   []string
+			redacted []string
+			kept     []string
+		}{
+			{name: "Default", redact: nil, redacted: []string{"Authorization: REDACTED", "Cookie: REDACTED"}, kept: []string{"X-Api-Key: secret"}},
+			{name: "Custom", redact: []string{"x-api-key"}, redacted: []string{"X-Api-Key: REDACTED"}, kept: []string{"Authorization: Bearer secret", "Cookie: session=secret"}},
+			{name: "Disabled", redact: []string{}, kept: []string{"Authorization: Bearer secret", "Cookie: session=secret", "X-Api-Key: secret"}},
+		}
+		for _, c := range cases {
+			c := c
+			t.Run(c.name, func(t *testing.T) {
+				req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("body"))
+				req.Header.Set("Authorization", "Bearer secret")
+				req.Header.Set("Cookie", "session=secret")
+				req.Header.Set("X-Api-Key", "secret")
+
+				buf := &bytes.Buffer{}
+				err := (&RequestDumperHook{Writer: buf, RedactHeaders: c.redact}).Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				dump := buf.String()
+				for _, line := range append(c.redacted, c.kept...) {
+					if !strings.Contains(dump, line+"\r\n") {
+						t.Errorf("Dump should contain %q, but got: %s", line, dump)
+					}
+				}
+				if req.Header.Get("Authorization") != "Bearer secret" || req.Header.Get("X-Api-Key") != "secret" {
+					t.Errorf("The request headers should not be modified, but got: %#v", req.Header)
+				}
+				if b, _ := io.ReadAll(req.Body); string(b) != "body" {
+					t.Errorf("The request body should be restored, but got: %q", b)
+				}
+			})
+		}
+	})
+
+	t.Run("SkipBody", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("large body"))
+
+		buf := &bytes.Buffer{}
+		err := (&RequestDumperHook{Writer: buf, SkipBody: true}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dump := buf.String()
+		if !strings.HasPrefix(dump, "POST /") || strings.Contains(dump, "large body") {
+			t.Errorf("Dump should not contain the body, but got: %s", dump)
+		}
+		if b, _ := io.ReadAll(req.Body); string(b) != "large body" {
+			t.Errorf("The request body should be kept, but got: %q", b)
+		}
+	})
+
+	t.Run("ShortWrite", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+
+		expected := &bytes.Buffer{}
+		err := (&RequestDumperHook{Writer: expected}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := &shortWriter{max: 3}
+		err = (&RequestDumperHook{Writer: w}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dump := w.buf.String(); dump != expected.String() {
+			t.Errorf("Dump should be %q, but got: %q", expected.String(), dump)
+		}
+	})
+}
+
+// shortWriter writes at most max bytes per call without error.
+type shortWriter struct {
+	buf bytes.Buffer
+	max int
+}
 
-	if dump := buf.String(); !strings.HasPrefix(dump, "GET /") {
-		t.Errorf("Unexpected dump: %s", dump)
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.max {
+		p = p[:w.max]
 	}
+	return w.buf.Write(p)
 }
 
 func TestRequestHeaderHook(t *testing.T) {
@@ -0,0 +1,64 @@
+package httpagent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const DefaultSignatureHeader = "X-Signature"
+
+var ErrMissingSignature = errors.New("httpagent: signature header is missing")
+
+type InvalidSignatureError struct {
+	Err error
+}
+
+func (e *InvalidSignatureError) Error() string {
+	return fmt.Sprintf("httpagent: invalid signature: %v", e.Err)
+}
+
+func (e *InvalidSignatureError) Unwrap() error {
+	return e.Err
+}
+
+type SignatureVerifier interface {
+	Verify(res *http.Response, body []byte, signature string) error
+}
+
+type SignatureVerifierFunc func(res *http.Response, body []byte, signature string) error
+
+func (f SignatureVerifierFunc) Verify(res *http.Response, body []byte, signature string) error {
+	return f(res, body, signature)
+}
+
+// RequireSignatureHeaderHook returns ErrMissingSignature if the signature header is absent,
+// or InvalidSignatureError if Verifier rejects it.
+type RequireSignatureHeaderHook struct {
+	Header   string
+	Verifier SignatureVerifier
+}
+
+func (h *RequireSignatureHeaderHook) Do(res *http.Response) error {
+	header := h.Header
+	if header == "" {
+		header = DefaultSignatureHeader
+	}
+
+	signature := res.Header.Get(header)
+	if signature == "" {
+		return ErrMissingSignature
+	}
+	if h.Verifier == nil {
+		return nil
+	}
+
+	body, err := readResponseBody(res)
+	if err != nil {
+		return err
+	}
+	if err := h.Verifier.Verify(res, body, signature); err != nil {
+		return &InvalidSignatureError{Err: err}
+	}
+	return nil
+}
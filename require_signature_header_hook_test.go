@@ -0,0 +1,78 @@
+package httpagent
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestRequireSignatureHeaderHook(t *testing.T) {
+	key := []byte("secret")
+	sign := func(body []byte) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	errMismatch := errors.New("mismatch")
+	verifier := SignatureVerifierFunc(func(res *http.Response, body []byte, signature string) error {
+		if !hmac.Equal([]byte(signature), []byte(sign(body))) {
+			return errMismatch
+		}
+		return nil
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		err := (&RequireSignatureHeaderHook{Verifier: verifier}).Do(res)
+		if !errors.Is(err, ErrMissingSignature) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Set("X-Signature", sign([]byte("NG")))
+		err := (&RequireSignatureHeaderHook{Verifier: verifier}).Do(res)
+
+		var sigErr *InvalidSignatureError
+		if !errors.As(err, &sigErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if !errors.Is(err, errMismatch) {
+			t.Errorf("Error should wrap the verifier error, but got: %#v", err)
+		}
+		if errors.Is(err, ErrMissingSignature) {
+			t.Errorf("Error should be distinct from the missing one, but got: %#v", err)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Set("X-Sig", sign([]byte("OK")))
+		err := (&RequireSignatureHeaderHook{Header: "X-Sig", Verifier: verifier}).Do(res)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "OK" {
+			t.Errorf("Body should be restored, but got: %s", s)
+		}
+	})
+
+	t.Run("NoVerifier", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Set("X-Signature", "anything")
+		err := (&RequireSignatureHeaderHook{}).Do(res)
+		if err != nil {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,17 @@
+package httpagent
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewClientWithResponseHeaderTimeout returns a client which fails if the response headers are not received in d after the request is written.
+// The transport is cloned from http.DefaultTransport.
+//
+// It does not limit reading the body, unlike Agent.DefaultTimeout which limits the whole request including the headers.
+// If both are set, the shorter one fires first while waiting for the headers.
+func NewClientWithResponseHeaderTimeout(d time.Duration) Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = d
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,58 @@
+package httpagent
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithResponseHeaderTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow-header" {
+			time.Sleep(200 * time.Millisecond)
+			return
+		}
+
+		// send headers soon, and the body slowly
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(200 * time.Millisecond)
+		_, _ = io.WriteString(w, "OK")
+	}))
+	t.Cleanup(ts.Close)
+
+	agent := NewAgent(NewClientWithResponseHeaderTimeout(50 * time.Millisecond))
+
+	t.Run("SlowHeader", func(t *testing.T) {
+		_, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL+"/slow-header", nil))
+
+		var urlErr *url.Error
+		if !errors.As(err, &urlErr) || !urlErr.Timeout() {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if !strings.Contains(err.Error(), "timeout awaiting response headers") {
+			t.Errorf("Should be the response header timeout, but got: %s", err)
+		}
+	})
+
+	t.Run("SlowBody", func(t *testing.T) {
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL+"/slow-body", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		defer res.Body.Close()
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if s := string(b); s != "OK" {
+			t.Errorf("Body should be OK, but got: %s", s)
+		}
+	})
+}
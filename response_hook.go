@@ -1,9 +1,13 @@
 package httpagent
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httputil"
+	"reflect"
+	"sync"
 )
 
 type ResponseHook interface {
@@ -24,7 +28,10 @@ func (h nopResponseHook) Do(_ *http.Response) error {
 	return nil
 }
 
+// ResponseHooks runs the hooks in order.
+// It is safe to modify the hooks while Do is running, and Do runs the hooks as of its start.
 type ResponseHooks struct {
+	mu    sync.RWMutex
 	hooks []ResponseHook
 }
 
@@ -47,17 +54,102 @@ func (h *ResponseHooks) Append(hook ResponseHook) {
 	}
 
 	// Optimize: flatten
+	appended := []ResponseHook{hook}
 	if hooks, ok := hook.(*ResponseHooks); ok {
-		h.hooks = append(h.hooks, hooks.hooks...)
+		appended = hooks.snapshot()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, appended...)
+}
+
+func (h *ResponseHooks) Prepend(hook ResponseHook) {
+	h.InsertAt(0, hook)
+}
+
+func (h *ResponseHooks) InsertAt(i int, hook ResponseHook) {
+	if hook == nil {
+		panic("nil hook")
+	}
+
+	// Optimize: flatten
+	inserted := []ResponseHook{hook}
+	if hooks, ok := hook.(*ResponseHooks); ok {
+		inserted = hooks.snapshot()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i < 0 || i > len(h.hooks) {
+		panic(fmt.Sprintf("hook index out of range [%d] with length %d", i, len(h.hooks)))
+	}
+
+	// Optimize: skip to add nop
+	if hook == NopResponseHook {
 		return
 	}
 
-	h.hooks = append(h.hooks, hook)
+	// build a new slice not to share the backing array with the inserted hooks
+	hooks := make([]ResponseHook, 0, len(h.hooks)+len(inserted))
+	hooks = append(hooks, h.hooks[:i]...)
+	hooks = append(hooks, inserted...)
+	hooks = append(hooks, h.hooks[i:]...)
+	h.hooks = hooks
+}
+
+func (h *ResponseHooks) RemoveAt(i int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeAt(i)
+}
+
+func (h *ResponseHooks) removeAt(i int) {
+	if i < 0 || i >= len(h.hooks) {
+		panic(fmt.Sprintf("hook index out of range [%d] with length %d", i, len(h.hooks)))
+	}
+
+	// build a new slice not to modify the hooks running by Do
+	hooks := make([]ResponseHook, 0, len(h.hooks)-1)
+	hooks = append(hooks, h.hooks[:i]...)
+	hooks = append(hooks, h.hooks[i+1:]...)
+	h.hooks = hooks
+}
+
+// Remove removes the first hook equal to the given one, and reports whether it is removed.
+// Hooks of non-comparable types such as ResponseHookFunc are never removed.
+func (h *ResponseHooks) Remove(hook ResponseHook) bool {
+	if hook == nil || !reflect.TypeOf(hook).Comparable() {
+		return false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, v := range h.hooks {
+		if v == hook {
+			h.removeAt(i)
+			return true
+		}
+	}
+	return false
 }
 
-func (h *ResponseHooks) Do(req *http.Response) (err error) {
-	for _, hook := range h.hooks {
-		err = hook.Do(req)
+func (h *ResponseHooks) Clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = nil
+}
+
+// snapshot returns the current hooks. The returned slice must not be modified.
+func (h *ResponseHooks) snapshot() []ResponseHook {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.hooks[:len(h.hooks):len(h.hooks)]
+}
+
+func (h *ResponseHooks) Do(res *http.Response) (err error) {
+	for _, hook := range h.snapshot() {
+		err = hook.Do(res)
 		if err != nil {
 			return
 		}
@@ -66,21 +158,45 @@ func (h *ResponseHooks) Do(req *http.Response) (err error) {
 }
 
 func (h *ResponseHooks) Len() int {
-	return len(h.hooks)
+	return len(h.snapshot())
+}
+
+// Hooks returns a copy of the hooks in the order they run.
+func (h *ResponseHooks) Hooks() []ResponseHook {
+	current := h.snapshot()
+	hooks := make([]ResponseHook, len(current))
+	copy(hooks, current)
+	return hooks
 }
 
 func (h *ResponseHooks) Clone() *ResponseHooks {
-	hooks := make([]ResponseHook, len(h.hooks))
-	copy(hooks, h.hooks)
-	return &ResponseHooks{hooks: hooks}
+	if h == nil {
+		return nil
+	}
+
+	return &ResponseHooks{hooks: h.Hooks()}
 }
 
 type ResponseDumperHook struct {
 	Writer io.Writer
+
+	// RedactHeaders masks the values of the headers in the dump. (nil means DefaultRedactHeaders, and empty means nothing)
+	RedactHeaders []string
+
+	// SkipBody dumps the headers only.
+	// It is inverted from a Body option so that the zero value keeps dumping the body as before.
+	SkipBody bool
 }
 
 func (h *ResponseDumperHook) Do(res *http.Response) error {
-	dump, err := httputil.DumpResponse(res, true)
+	// dump a clone to keep the real headers intact
+	clone := *res
+	clone.Header = redactHeader(res.Header, redactHeaders(h.RedactHeaders))
+
+	dump, err := httputil.DumpResponse(&clone, !h.SkipBody)
+
+	// the dump has drained and restored the body
+	res.Body = clone.Body
 	if err != nil {
 		return err
 	}
@@ -88,7 +204,7 @@ func (h *ResponseDumperHook) Do(res *http.Response) error {
 
 	var n, wrote int
 	for wrote < len(dump) {
-		n, err = h.Writer.Write(dump)
+		n, err = h.Writer.Write(dump[wrote:])
 		if err != nil {
 			return err
 		}
@@ -97,3 +213,47 @@ func (h *ResponseDumperHook) Do(res *http.Response) error {
 	}
 	return err
 }
+
+type ResponseHeaderHook struct {
+	Header       http.Header
+	Add          bool
+	SkipIfExists bool
+	Rename       map[string]string
+}
+
+func (h *ResponseHeaderHook) Do(res *http.Response) error {
+	for from, to := range h.Rename {
+		values, ok := res.Header[http.CanonicalHeaderKey(from)]
+		if !ok {
+			continue
+		}
+
+		res.Header.Del(from)
+		res.Header[http.CanonicalHeaderKey(to)] = values
+	}
+
+	for key := range h.Header {
+		if h.SkipIfExists {
+			if _, ok := res.Header[http.CanonicalHeaderKey(key)]; ok {
+				continue
+			}
+		}
+
+		value := h.Header.Get(key)
+		if h.Add {
+			res.Header.Add(key, value)
+		} else {
+			res.Header.Set(key, value)
+		}
+	}
+
+	return nil
+}
+
+func setResponseContextValue(res *http.Response, key, value interface{}) {
+	req := res.Request
+	if req == nil {
+		req = &http.Request{}
+	}
+	res.Request = req.WithContext(context.WithValue(req.Context(), key, value))
+}
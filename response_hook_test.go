@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/textproto"
 	"strings"
 	"testing"
 
@@ -150,14 +151,373 @@ func TestResponseHooks(t *testing.T) {
 	})
 }
 
-func TestResponseDumperHook(t *testing.T) {
-	buf := &bytes.Buffer{}
-	err := (&ResponseDumperHook{Writer: buf}).Do(mustNewResponse(t, http.MethodGet, "http://example.com/", nil))
-	if err != nil {
-		t.Error(err)
+func TestResponseHooksInsert(t *testing.T) {
+	var order []string
+	hook := func(name string) ResponseHook {
+		return ResponseHookFunc(func(_ *http.Response) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	t.Run("Order", func(t *testing.T) {
+		order = nil
+		hooks := NewResponseHooks(hook("b"))
+		hooks.Append(hook("d"))
+		hooks.Prepend(hook("a"))
+		hooks.InsertAt(2, hook("c"))
+		hooks.InsertAt(hooks.Len(), hook("f"))
+		hooks.InsertAt(4, NewResponseHooks(hook("e1"), NopResponseHook, hook("e2")))
+		hooks.Prepend(NopResponseHook)
+		if hooks.Len() != 7 {
+			t.Errorf("Should flatten hooks and skip nop, but got: %#v", hooks)
+		}
+
+		err := hooks.Do(mustNewResponse(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"a", "b", "c", "d", "e1", "e2", "f"}
+		if diff := cmp.Diff(expected, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+	})
+
+	t.Run("NotShareBackingArray", func(t *testing.T) {
+		order = nil
+		inner := NewResponseHooks(hook("x"), hook("y"))
+		hooks := NewResponseHooks(hook("a"))
+		hooks.Prepend(inner)
+		inner.Append(hook("z"))
+
+		err := hooks.Do(mustNewResponse(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"x", "y", "a"}
+		if diff := cmp.Diff(expected, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+	})
+
+	for _, i := range []int{-1, 2} {
+		i := i
+		t.Run(fmt.Sprintf("Panic/%d", i), func(t *testing.T) {
+			hooks := NewResponseHooks(hook("a"))
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("The code did not panic")
+				}
+			}()
+			hooks.InsertAt(i, hook("b"))
+		})
+	}
+
+	t.Run("PanicNil", func(t *testing.T) {
+		hooks := NewResponseHooks()
+
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("The code did not panic")
+			}
+		}()
+		hooks.Prepend(nil)
+	})
+}
+
+func TestResponseHooksRemove(t *testing.T) {
+	var order []string
+	hook := func(name string) ResponseHook {
+		return ResponseHookFunc(func(_ *http.Response) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	t.Run("RemoveAt", func(t *testing.T) {
+		order = nil
+		hooks := NewResponseHooks(hook("a"), hook("b"), hook("c"), hook("d"))
+		hooks.RemoveAt(1)
+		hooks.RemoveAt(hooks.Len() - 1)
+
+		err := hooks.Do(mustNewResponse(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"a", "c"}, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		order = nil
+		target := &ResponseHeaderHook{}
+		hooks := NewResponseHooks(hook("a"), target, hook("b"), target)
+
+		if !hooks.Remove(target) {
+			t.Error("Remove should return true")
+		}
+		if hooks.Len() != 3 {
+			t.Errorf("Only the first match should be removed, but got: %#v", hooks)
+		}
+		if !hooks.Remove(target) || hooks.Remove(target) {
+			t.Error("Remove should return true only while the hook remains")
+		}
+		if hooks.Remove(&ResponseHeaderHook{}) {
+			t.Error("Remove should not remove another pointer")
+		}
+		if hooks.Remove(hook("a")) {
+			t.Error("Remove should return false for a non-comparable hook")
+		}
+
+		err := hooks.Do(mustNewResponse(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"a", "b"}, order); diff != "" {
+			t.Errorf("Unexpected order: %s", diff)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		hooks := NewResponseHooks(hook("a"), hook("b"))
+		hooks.Clear()
+		if hooks.Len() != 0 {
+			t.Errorf("Should be empty, but got: %#v", hooks)
+		}
+	})
+
+	for _, i := range []int{-1, 1} {
+		i := i
+		t.Run(fmt.Sprintf("Panic/%d", i), func(t *testing.T) {
+			hooks := NewResponseHooks(hook("a"))
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("The code did not panic")
+				}
+			}()
+			hooks.RemoveAt(i)
+		})
+	}
+}
+
+func TestResponseHooksHooks(t *testing.T) {
+	a, b := &ResponseHeaderHook{}, &ResponseHeaderHook{}
+	hooks := NewResponseHooks(a, b)
+
+	got := hooks.Hooks()
+	if len(got) != 2 || got[0] != a || got[1] != b {
+		t.Fatalf("Hooks should be [a b], but got: %#v", got)
 	}
 
-	if dump := buf.String(); !strings.HasPrefix(dump, "HTTP/1.0 200 OK") {
-		t.Errorf("Unexpected dump: %s", dump)
+	got[0], got[1] = NopResponseHook, NopResponseHook
+	if list := hooks.Hooks(); list[0] != a || list[1] != b {
+		t.Errorf("Mutating the returned slice should not affect the hooks, but got: %#v", list)
 	}
 }
+
+func TestResponseHooksConcurrent(t *testing.T) {
+	hooks := NewResponseHooks()
+	hook := ResponseHookFunc(func(res *http.Response) error { return nil })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			hooks.Append(hook)
+			hooks.InsertAt(0, NewResponseHooks(hook))
+			hooks.RemoveAt(hooks.Len() - 1)
+			if i%10 == 0 {
+				hooks.Clear()
+			}
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if err := hooks.Do(mustNewResponse(t, http.MethodGet, "http://example.com/", nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
+
+func TestResponseDumperHook(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := (&ResponseDumperHook{Writer: buf}).Do(mustNewResponse(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Error(err)
+		}
+
+		if dump := buf.String(); !strings.HasPrefix(dump, "HTTP/1.0 200 OK") {
+			t.Errorf("Unexpected dump: %s", dump)
+		}
+	})
+
+	t.Run("RedactHeaders", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		newResponse := func() *http.Response {
+			return mockhttp.NewResponseMock(http.StatusOK, map[string]string{
+				"Set-Cookie": "session=secret",
+				"X-Api-Key":  "secret",
+			}, []byte("body")).MakeResponse(req)
+		}
+
+		t.Run("Default", func(t *testing.T) {
+			res := newResponse()
+			buf := &bytes.Buffer{}
+			err := (&ResponseDumperHook{Writer: buf}).Do(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if dump := buf.String(); !strings.Contains(dump, "Set-Cookie: REDACTED\r\n") || !strings.Contains(dump, "X-Api-Key: secret\r\n") {
+				t.Errorf("Unexpected dump: %s", dump)
+			}
+			if res.Header.Get("Set-Cookie") != "session=secret" {
+				t.Errorf("The response headers should not be modified, but got: %#v", res.Header)
+			}
+			if b, _ := io.ReadAll(res.Body); string(b) != "body" {
+				t.Errorf("The response body should be restored, but got: %q", b)
+			}
+		})
+
+		t.Run("Disabled", func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := (&ResponseDumperHook{Writer: buf, RedactHeaders: []string{}}).Do(newResponse())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if dump := buf.String(); !strings.Contains(dump, "Set-Cookie: session=secret\r\n") {
+				t.Errorf("Unexpected dump: %s", dump)
+			}
+		})
+	})
+
+	t.Run("SkipBody", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		res := mockhttp.NewResponseMock(http.StatusOK, nil, []byte("large body")).MakeResponse(req)
+
+		buf := &bytes.Buffer{}
+		err := (&ResponseDumperHook{Writer: buf, SkipBody: true}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dump := buf.String()
+		if !strings.HasPrefix(dump, "HTTP/1.0 200 OK") || strings.Contains(dump, "large body") {
+			t.Errorf("Dump should not contain the body, but got: %s", dump)
+		}
+		if b, _ := io.ReadAll(res.Body); string(b) != "large body" {
+			t.Errorf("The response body should be kept, but got: %q", b)
+		}
+	})
+
+	t.Run("ShortWrite", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+
+		expected := &bytes.Buffer{}
+		err := (&ResponseDumperHook{Writer: expected}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := &shortWriter{max: 3}
+		err = (&ResponseDumperHook{Writer: w}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dump := w.buf.String(); dump != expected.String() {
+			t.Errorf("Dump should be %q, but got: %q", expected.String(), dump)
+		}
+	})
+}
+
+func TestResponseHeaderHook(t *testing.T) {
+	t.Run("Set", func(t *testing.T) {
+		hook := &ResponseHeaderHook{Header: http.Header{}}
+		hook.Header.Set("Foo", "hoge")
+		hook.Header.Set("Bar", "fuga")
+
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Set("Bar", "piyo")
+		err := hook.Do(res)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if res.Header.Get("Foo") != "hoge" {
+			t.Errorf("Foo header should be hoge, but got: %#v", res.Header)
+		}
+		if res.Header.Get("Bar") != "fuga" {
+			t.Errorf("Bar header should be fuga, but got: %#v", res.Header)
+		}
+	})
+
+	t.Run("Add", func(t *testing.T) {
+		hook := &ResponseHeaderHook{Header: http.Header{}, Add: true}
+		hook.Header.Set("Foo", "hoge")
+		hook.Header.Set("Bar", "fuga")
+
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Set("Bar", "piyo")
+		err := hook.Do(res)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if res.Header.Get("Foo") != "hoge" {
+			t.Errorf("Foo header should be hoge, but got: %#v", res.Header)
+		}
+		if bar := res.Header[textproto.CanonicalMIMEHeaderKey("Bar")]; !cmp.Equal(bar, []string{"piyo", "fuga"}) {
+			t.Errorf(`Bar header should be ["piyo", "fuga"], but got:  %#v`, bar)
+		}
+	})
+
+	t.Run("SkipIfExists", func(t *testing.T) {
+		hook := &ResponseHeaderHook{Header: http.Header{}, SkipIfExists: true}
+		hook.Header.Set("Foo", "hoge")
+		hook.Header.Set("Bar", "fuga")
+
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Set("Bar", "piyo")
+		err := hook.Do(res)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if res.Header.Get("Foo") != "hoge" {
+			t.Errorf("Foo header should be hoge, but got: %#v", res.Header)
+		}
+		if bar := res.Header[textproto.CanonicalMIMEHeaderKey("Bar")]; !cmp.Equal(bar, []string{"piyo"}) {
+			t.Errorf(`Bar header should be ["piyo"], but got:  %#v`, bar)
+		}
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		hook := &ResponseHeaderHook{Rename: map[string]string{"x-old-name": "X-New-Name", "X-Missing": "X-Other"}}
+
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Add("X-Old-Name", "a")
+		res.Header.Add("X-Old-Name", "b")
+		err := hook.Do(res)
+		if err != nil {
+			t.Error(err)
+		}
+
+		if _, ok := res.Header["X-Old-Name"]; ok {
+			t.Errorf("X-Old-Name header should be removed, but got: %#v", res.Header)
+		}
+		if v := res.Header.Values("X-New-Name"); !cmp.Equal(v, []string{"a", "b"}) {
+			t.Errorf(`X-New-Name header should be ["a", "b"], but got: %#v`, v)
+		}
+		if _, ok := res.Header["X-Other"]; ok {
+			t.Errorf("X-Other header should not be added, but got: %#v", res.Header)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package httpagent
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrBodyNotReplayable = errors.New("httpagent: request body is not replayable for retry")
+
+var DefaultRetryStatuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+var DefaultBackoff = ExponentialBackoff(100*time.Millisecond, 2)
+
+// ExponentialBackoff returns a backoff which waits base*factor^(attempt-1) before the attempt-th retry.
+func ExponentialBackoff(base time.Duration, factor float64) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		return time.Duration(float64(base) * math.Pow(factor, float64(attempt-1)))
+	}
+}
+
+func (a *Agent) sendWithRetry(client Client, req *http.Request) (*http.Response, error) {
+	res, err := a.send(client, req)
+	for attempt := 1; attempt <= a.MaxRetries && a.shouldRetry(req, res, err); attempt++ {
+		next := req.Clone(req.Context())
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				DrainAndClose(res)
+				return nil, ErrBodyNotReplayable
+			}
+
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				DrainAndClose(res)
+				return nil, bodyErr
+			}
+			next.Body = body
+		}
+		DrainAndClose(res)
+
+		if err := a.sleep(req, res, attempt); err != nil {
+			return nil, err
+		}
+		res, err = a.send(client, next)
+	}
+	return res, err
+}
+
+// isIdempotent reports whether the request can be sent twice safely, as net/http decides to retry it.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, ok := req.Header["Idempotency-Key"]
+	if !ok {
+		_, ok = req.Header["X-Idempotency-Key"]
+	}
+	return ok
+}
+
+// isContextError reports whether the error is caused by the context being done.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (a *Agent) shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if err != nil {
+		// the caller gave up
+		if isContextError(err) {
+			return false
+		}
+
+		// the server may have processed the request
+		if !a.RetryNonIdempotent && !isIdempotent(req) {
+			return false
+		}
+
+		// *url.Error is also a net.Error, so check the cause
+		var opErr *net.OpError
+		if errors.As(err, &opErr) {
+			return true
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+
+	statuses := a.RetryStatuses
+	if statuses == nil {
+		statuses = DefaultRetryStatuses
+	}
+	for _, status := range statuses {
+		if status == res.StatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Agent) sleep(req *http.Request, res *http.Response, attempt int) error {
+	backoff := a.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
+	// prefer Retry-After to the backoff, but never wait longer than the limit
+	d, ok := ParseRetryAfter(res, a.clock().Now())
+	if !ok {
+		d = backoff(attempt)
+	} else if a.MaxRetryAfter > 0 && d > a.MaxRetryAfter {
+		d = a.MaxRetryAfter
+	}
+	return a.wait(req.Context(), d)
+}
+
+// ParseRetryAfter parses the Retry-After header in either delay-seconds or HTTP-date form.
+// A date in the past is clamped to zero.
+func ParseRetryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(res.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	var d time.Duration
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		d = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		d = date.Sub(now)
+	} else {
+		return 0, false
+	}
+
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
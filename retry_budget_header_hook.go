@@ -0,0 +1,63 @@
+package httpagent
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultRetryBudgetHeader = "X-Retry-Budget"
+
+type RetryBudgetHeaderHook struct {
+	Header      string
+	MaxAttempts int
+	Clock       Clock
+
+	// Agent is the agent to send the request. If set, the budget takes the timeout and the retries of it into account.
+	// MaxAttempts is derived from Agent.MaxRetries unless it is set.
+	Agent *Agent
+}
+
+func (h *RetryBudgetHeaderHook) Do(req *http.Request) error {
+	header := h.Header
+	if header == "" {
+		header = DefaultRetryBudgetHeader
+	}
+
+	clock := h.Clock
+	if clock == nil {
+		clock = RealClock
+	}
+
+	remaining, ok := time.Duration(0), false
+	if deadline, hasDeadline := req.Context().Deadline(); hasDeadline {
+		remaining, ok = deadline.Sub(clock.Now()), true
+	}
+	maxAttempts := h.MaxAttempts
+	if h.Agent != nil {
+		// the agent applies its timeout after the request hooks
+		if timeout := h.Agent.timeout(req.Context()); timeout > 0 && (!ok || timeout < remaining) {
+			remaining, ok = timeout, true
+		}
+		if maxAttempts <= 0 {
+			maxAttempts = h.Agent.MaxRetries + 1
+		}
+	}
+
+	var params []string
+	if ok {
+		if remaining < 0 {
+			remaining = 0
+		}
+		params = append(params, "timeout="+strconv.FormatInt(int64(remaining/time.Millisecond), 10)+"ms")
+	}
+	if maxAttempts > 0 {
+		params = append(params, "attempts="+strconv.Itoa(maxAttempts))
+	}
+
+	if len(params) != 0 {
+		req.Header.Set(header, strings.Join(params, ", "))
+	}
+	return nil
+}
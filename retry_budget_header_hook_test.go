@@ -0,0 +1,123 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestRetryBudgetHeaderHook(t *testing.T) {
+	clock := newFakeClock()
+
+	t.Run("WithDeadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(1500*time.Millisecond))
+		defer cancel()
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req = req.WithContext(ctx)
+
+		err := (&RetryBudgetHeaderHook{MaxAttempts: 3, Clock: clock}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := req.Header.Get("X-Retry-Budget"); v != "timeout=1500ms, attempts=3" {
+			t.Errorf("Unexpected header: %q", v)
+		}
+	})
+
+	t.Run("ExpiredDeadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(-time.Second))
+		defer cancel()
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req = req.WithContext(ctx)
+
+		err := (&RetryBudgetHeaderHook{Header: "Budget", Clock: clock}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := req.Header.Get("Budget"); v != "timeout=0ms" {
+			t.Errorf("Unexpected header: %q", v)
+		}
+	})
+
+	t.Run("WithoutDeadline", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&RetryBudgetHeaderHook{MaxAttempts: 3}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := req.Header.Get("X-Retry-Budget"); v != "attempts=3" {
+			t.Errorf("Unexpected header: %q", v)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&RetryBudgetHeaderHook{}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := req.Header["X-Retry-Budget"]; ok {
+			t.Errorf("Header should not be set, but got: %#v", req.Header)
+		}
+	})
+	t.Run("Agent", func(t *testing.T) {
+		var header string
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			header = req.Header.Get("X-Retry-Budget")
+			return mockhttp.NewResponseMock(http.StatusOK, nil, nil).MakeResponse(req), nil
+		}))
+		agent.DefaultTimeout = 2 * time.Second
+		agent.MaxRetries = 2
+		agent.RequestHooks.Append(&RetryBudgetHeaderHook{Clock: clock, Agent: agent})
+
+		cases := []struct {
+			name     string
+			ctx      func() (context.Context, context.CancelFunc)
+			expected string
+		}{
+			{
+				name: "DefaultTimeout",
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithDeadline(context.Background(), clock.Now().Add(time.Minute))
+				},
+				expected: "timeout=2000ms, attempts=3",
+			},
+			{
+				name: "ContextTimeout",
+				ctx: func() (context.Context, context.CancelFunc) {
+					return WithTimeout(context.Background(), 500*time.Millisecond), func() {}
+				},
+				expected: "timeout=500ms, attempts=3",
+			},
+			{
+				name: "EarlierDeadline",
+				ctx: func() (context.Context, context.CancelFunc) {
+					return context.WithDeadline(context.Background(), clock.Now().Add(time.Second))
+				},
+				expected: "timeout=1000ms, attempts=3",
+			},
+		}
+		for _, c := range cases {
+			c := c
+			t.Run(c.name, func(t *testing.T) {
+				ctx, cancel := c.ctx()
+				defer cancel()
+
+				req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+				res, err := agent.Do(req.WithContext(ctx))
+				if err != nil {
+					t.Fatalf("Unexpected error is occurred: %#v", err)
+				}
+				res.Body.Close()
+				if header != c.expected {
+					t.Errorf("Header should be %q, but got: %q", c.expected, header)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,454 @@
+package httpagent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, 2)
+
+	var got []time.Duration
+	for attempt := 1; attempt <= 4; attempt++ {
+		got = append(got, backoff(attempt))
+	}
+
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Unexpected backoff: %s", diff)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "Seconds", value: "120", expected: 2 * time.Minute, ok: true},
+		{name: "ZeroSeconds", value: "0", expected: 0, ok: true},
+		{name: "NegativeSeconds", value: "-5", expected: 0, ok: true},
+		{name: "Date", value: "Sat, 01 Jan 2022 00:00:30 GMT", expected: 30 * time.Second, ok: true},
+		{name: "PastDate", value: "Fri, 31 Dec 2021 23:59:00 GMT", expected: 0, ok: true},
+		{name: "Absent", value: "", expected: 0, ok: false},
+		{name: "Invalid", value: "soon", expected: 0, ok: false},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+			if c.value != "" {
+				res.Header.Set("Retry-After", c.value)
+			}
+
+			d, ok := ParseRetryAfter(res, now)
+			if d != c.expected || ok != c.ok {
+				t.Errorf("Should be (%s, %v), but got: (%s, %v)", c.expected, c.ok, d, ok)
+			}
+		})
+	}
+}
+
+func TestAgentRetry(t *testing.T) {
+	noBackoff := func(int) time.Duration { return 0 }
+
+	// setup returns a server which fails with the status for the first n requests
+	setup := func(t *testing.T, status, n int) (*httptest.Server, *int32, *[]string) {
+		var calls int32
+		var bodies []string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			if c := atomic.AddInt32(&calls, 1); int(c) <= n {
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = io.WriteString(w, "OK")
+		}))
+		t.Cleanup(ts.Close)
+		return ts, &calls, &bodies
+	}
+
+	t.Run("Retry", func(t *testing.T) {
+		ts, calls, bodies := setup(t, http.StatusServiceUnavailable, 2)
+
+		agent := NewAgent(http.DefaultClient)
+		agent.MaxRetries = 2
+		agent.Backoff = noBackoff
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodPost, ts.URL, strings.NewReader("hello")))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode should be 200, but got: %d", res.StatusCode)
+		}
+		if c := atomic.LoadInt32(calls); c != 3 {
+			t.Errorf("Calls should be 3, but got: %d", c)
+		}
+		if diff := cmp.Diff([]string{"hello", "hello", "hello"}, *bodies); diff != "" {
+			t.Errorf("Body should be rewound: %s", diff)
+		}
+	})
+
+	t.Run("NotReplayable", func(t *testing.T) {
+		ts, calls, _ := setup(t, http.StatusServiceUnavailable, 1)
+
+		agent := NewAgent(http.DefaultClient)
+		agent.MaxRetries = 1
+		agent.Backoff = noBackoff
+
+		// io.MultiReader hides the type to http.NewRequest, so GetBody is not installed
+		req := mustNewRequest(t, http.MethodPost, ts.URL, io.MultiReader(strings.NewReader("hello")))
+		_, err := agent.Do(req)
+		if !errors.Is(err, ErrBodyNotReplayable) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if c := atomic.LoadInt32(calls); c != 1 {
+			t.Errorf("Calls should be 1, but got: %d", c)
+		}
+	})
+
+	t.Run("BufferRequestBody", func(t *testing.T) {
+		ts, calls, bodies := setup(t, http.StatusServiceUnavailable, 1)
+
+		agent := NewAgent(http.DefaultClient)
+		agent.MaxRetries = 1
+		agent.Backoff = noBackoff
+
+		req := mustNewRequest(t, http.MethodPost, ts.URL, io.MultiReader(strings.NewReader("hello")))
+		req.Header.Set("Content-Length", "999")
+		if err := BufferRequestBody(req); err != nil {
+			t.Fatal(err)
+		}
+		if req.ContentLength != 5 {
+			t.Errorf("ContentLength should be 5, but got: %d", req.ContentLength)
+		}
+		if contentLength := req.Header.Get("Content-Length"); contentLength != "5" {
+			t.Errorf("Content-Length header should be 5, but got: %s", contentLength)
+		}
+
+		res, err := agent.Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+		if c := atomic.LoadInt32(calls); c != 2 {
+			t.Errorf("Calls should be 2, but got: %d", c)
+		}
+		if diff := cmp.Diff([]string{"hello", "hello"}, *bodies); diff != "" {
+			t.Errorf("Body should be replayed: %s", diff)
+		}
+	})
+
+	t.Run("ContentLength", func(t *testing.T) {
+		var mu sync.Mutex
+		var lengths []int64
+		var calls int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			lengths = append(lengths, r.ContentLength)
+			mu.Unlock()
+			if atomic.AddInt32(&calls, 1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = io.WriteString(w, "OK")
+		}))
+		t.Cleanup(ts.Close)
+
+		agent := NewAgent(http.DefaultClient)
+		agent.MaxRetries = 2
+		agent.Backoff = noBackoff
+
+		for name, buffer := range map[string]bool{"Known": false, "Buffered": true} {
+			atomic.StoreInt32(&calls, 0)
+			mu.Lock()
+			lengths = nil
+			mu.Unlock()
+
+			body := io.Reader(strings.NewReader("hello"))
+			if buffer {
+				body = io.MultiReader(body)
+			}
+			req := mustNewRequest(t, http.MethodPost, ts.URL, body)
+			if buffer {
+				if err := BufferRequestBody(req); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := agent.Do(req)
+			if err != nil {
+				t.Fatalf("%s: Unexpected error is occurred: %#v", name, err)
+			}
+			res.Body.Close()
+
+			mu.Lock()
+			if diff := cmp.Diff([]int64{5, 5, 5}, lengths); diff != "" {
+				t.Errorf("%s: Content-Length should be kept across retries: %s", name, diff)
+			}
+			mu.Unlock()
+		}
+	})
+
+	t.Run("GiveUp", func(t *testing.T) {
+		ts, calls, _ := setup(t, http.StatusBadGateway, 5)
+
+		agent := NewAgent(http.DefaultClient)
+		agent.MaxRetries = 2
+		agent.Backoff = noBackoff
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL, nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusBadGateway {
+			t.Errorf("StatusCode should be 502, but got: %d", res.StatusCode)
+		}
+		if c := atomic.LoadInt32(calls); c != 3 {
+			t.Errorf("Calls should be 3, but got: %d", c)
+		}
+	})
+
+	t.Run("NotRetryable", func(t *testing.T) {
+		ts, calls, _ := setup(t, http.StatusInternalServerError, 1)
+
+		agent := NewAgent(http.DefaultClient)
+		agent.MaxRetries = 2
+		agent.Backoff = noBackoff
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL, nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+		if c := atomic.LoadInt32(calls); c != 1 {
+			t.Errorf("Calls should be 1, but got: %d", c)
+		}
+	})
+
+	t.Run("NetworkError", func(t *testing.T) {
+		var calls int32
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}))
+		agent.MaxRetries = 1
+		agent.Backoff = noBackoff
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("StatusCode should be 200, but got: %d", res.StatusCode)
+		}
+		if c := atomic.LoadInt32(&calls); c != 2 {
+			t.Errorf("Calls should be 2, but got: %d", c)
+		}
+	})
+
+	t.Run("NetworkErrorNonIdempotent", func(t *testing.T) {
+		cases := []struct {
+			name        string
+			header      string
+			optIn       bool
+			expectCalls int32
+		}{
+			{name: "POST", expectCalls: 1},
+			{name: "IdempotencyKey", header: "Idempotency-Key", expectCalls: 2},
+			{name: "XIdempotencyKey", header: "X-Idempotency-Key", expectCalls: 2},
+			{name: "OptIn", optIn: true, expectCalls: 2},
+		}
+		for _, c := range cases {
+			c := c
+			t.Run(c.name, func(t *testing.T) {
+				var calls int32
+				agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+					if atomic.AddInt32(&calls, 1) == 1 {
+						return nil, &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+					}
+					return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+				}))
+				agent.MaxRetries = 1
+				agent.Backoff = noBackoff
+				agent.RetryNonIdempotent = c.optIn
+
+				req := mustNewRequest(t, http.MethodPost, "http://example.com/", strings.NewReader("body"))
+				if c.header != "" {
+					req.Header.Set(c.header, "key")
+				}
+				res, err := agent.Do(req)
+				if err == nil {
+					res.Body.Close()
+				}
+				if n := atomic.LoadInt32(&calls); n != c.expectCalls {
+					t.Errorf("Calls should be %d, but got: %d", c.expectCalls, n)
+				}
+			})
+		}
+	})
+
+	t.Run("NotRetryableError", func(t *testing.T) {
+		cases := []struct {
+			name string
+			err  error
+		}{
+			{name: "UnsupportedScheme", err: &url.Error{Op: "Get", URL: "ftp://example.com/", Err: errors.New(`unsupported protocol scheme "ftp"`)}},
+			{name: "DeadlineExceeded", err: &url.Error{Op: "Get", URL: "http://example.com/", Err: context.DeadlineExceeded}},
+			{name: "Canceled", err: &url.Error{Op: "Get", URL: "http://example.com/", Err: context.Canceled}},
+		}
+		for _, c := range cases {
+			c := c
+			t.Run(c.name, func(t *testing.T) {
+				var calls int32
+				agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+					atomic.AddInt32(&calls, 1)
+					return nil, c.err
+				}))
+				agent.MaxRetries = 2
+				agent.Backoff = noBackoff
+
+				_, err := agent.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+				if err != c.err {
+					t.Errorf("Error should be %#v, but got: %#v", c.err, err)
+				}
+				if n := atomic.LoadInt32(&calls); n != 1 {
+					t.Errorf("Calls should be 1, but got: %d", n)
+				}
+			})
+		}
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		ts, calls, _ := setup(t, http.StatusServiceUnavailable, 1)
+
+		clock := newFakeClock()
+		agent := NewAgent(http.DefaultClient)
+		agent.MaxRetries = 1
+		agent.Clock = clock
+
+		done := make(chan error, 1)
+		go func() {
+			res, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL, nil))
+			if err == nil {
+				res.Body.Close()
+			}
+			done <- err
+		}()
+
+		clock.WaitTimers(t, 1)
+		if c := atomic.LoadInt32(calls); c != 1 {
+			t.Errorf("Calls should be 1 while backing off, but got: %d", c)
+		}
+		clock.Advance(100 * time.Millisecond)
+		if err := <-done; err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if c := atomic.LoadInt32(calls); c != 2 {
+			t.Errorf("Calls should be 2, but got: %d", c)
+		}
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		cases := []struct {
+			name          string
+			retryAfter    string
+			maxRetryAfter time.Duration
+			expected      time.Duration
+		}{
+			// the default backoff (100ms) should not be used
+			{name: "Wait", retryAfter: "3", maxRetryAfter: time.Minute, expected: 3 * time.Second},
+			{name: "Limit", retryAfter: "3600", maxRetryAfter: 5 * time.Second, expected: 5 * time.Second},
+			{name: "NoLimit", retryAfter: "3600", expected: time.Hour},
+		}
+		for _, c := range cases {
+			c := c
+			t.Run(c.name, func(t *testing.T) {
+				var calls int32
+				ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if atomic.AddInt32(&calls, 1) == 1 {
+						w.Header().Set("Retry-After", c.retryAfter)
+						w.WriteHeader(http.StatusTooManyRequests)
+						return
+					}
+					_, _ = io.WriteString(w, "OK")
+				}))
+				t.Cleanup(ts.Close)
+
+				clock := newFakeClock()
+				agent := NewAgent(http.DefaultClient)
+				agent.MaxRetries = 1
+				agent.MaxRetryAfter = c.maxRetryAfter
+				agent.RetryStatuses = []int{http.StatusTooManyRequests}
+				agent.Clock = clock
+
+				done := make(chan error, 1)
+				go func() {
+					res, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL, nil))
+					if err == nil {
+						res.Body.Close()
+					}
+					done <- err
+				}()
+
+				clock.WaitTimers(t, 1)
+				clock.Advance(c.expected - time.Millisecond)
+				if n := atomic.LoadInt32(&calls); n != 1 {
+					t.Errorf("Calls should be 1 until %s, but got: %d", c.expected, n)
+				}
+				clock.Advance(time.Millisecond)
+				if err := <-done; err != nil {
+					t.Fatalf("Unexpected error is occurred: %#v", err)
+				}
+				if n := atomic.LoadInt32(&calls); n != 2 {
+					t.Errorf("Calls should be 2, but got: %d", n)
+				}
+			})
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ts, calls, _ := setup(t, http.StatusServiceUnavailable, 1)
+
+		clock := newFakeClock()
+		agent := NewAgent(http.DefaultClient)
+		agent.MaxRetries = 1
+		agent.Clock = clock
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, err := agent.Do(mustNewRequest(t, http.MethodGet, ts.URL, nil).WithContext(ctx))
+			done <- err
+		}()
+
+		clock.WaitTimers(t, 1)
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+		if c := atomic.LoadInt32(calls); c != 1 {
+			t.Errorf("Calls should be 1, but got: %d", c)
+		}
+	})
+}
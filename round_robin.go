@@ -0,0 +1,102 @@
+package httpagent
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var ErrNoHosts = errors.New("httpagent: no hosts for round robin")
+
+// RoundRobinClient rewrites the scheme and host of each request to the hosts in turn.
+// If FailureCooldown is set, a host which returned an error or 5xx is skipped for the duration unless all hosts are failing.
+type RoundRobinClient struct {
+	Client          Client
+	FailureCooldown time.Duration
+	Clock           Clock
+
+	hosts       []*url.URL
+	mu          sync.Mutex
+	next        int
+	failedUntil []time.Time
+}
+
+var _ Client = &RoundRobinClient{}
+
+func NewRoundRobinClient(client Client, hosts ...string) (*RoundRobinClient, error) {
+	if len(hosts) == 0 {
+		return nil, ErrNoHosts
+	}
+
+	c := &RoundRobinClient{
+		Client:      client,
+		hosts:       make([]*url.URL, len(hosts)),
+		failedUntil: make([]time.Time, len(hosts)),
+	}
+	for i, host := range hosts {
+		u, err := url.Parse(host)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("httpagent: invalid base URL for round robin: %s", host)
+		}
+		c.hosts[i] = u
+	}
+	return c, nil
+}
+
+func (c *RoundRobinClient) Do(req *http.Request) (*http.Response, error) {
+	if c.Client == nil {
+		return nil, ErrNoClient
+	}
+	if len(c.hosts) == 0 {
+		return nil, ErrNoHosts
+	}
+
+	i := c.pick()
+	host := c.hosts[i]
+
+	next := req.Clone(req.Context())
+	next.URL.Scheme = host.Scheme
+	next.URL.Host = host.Host
+	next.Host = ""
+
+	res, err := c.Client.Do(next)
+	if c.FailureCooldown > 0 && (err != nil || res.StatusCode >= 500) {
+		c.mu.Lock()
+		c.failedUntil[i] = c.clock().Now().Add(c.FailureCooldown)
+		c.mu.Unlock()
+	}
+	return res, err
+}
+
+func (c *RoundRobinClient) clock() Clock {
+	if c.Clock == nil {
+		return RealClock
+	}
+	return c.Clock
+}
+
+func (c *RoundRobinClient) pick() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock().Now()
+	n := len(c.hosts)
+	for j := 0; j < n; j++ {
+		i := (c.next + j) % n
+		if !now.Before(c.failedUntil[i]) {
+			c.next = i + 1
+			return i
+		}
+	}
+
+	// all hosts are failing
+	i := c.next % n
+	c.next = i + 1
+	return i
+}
@@ -0,0 +1,118 @@
+package httpagent
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestRoundRobinClient(t *testing.T) {
+	newRecorder := func(status func(host string) int) (Client, func() map[string]int) {
+		var mu sync.Mutex
+		counts := map[string]int{}
+		client := ClientFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			counts[req.URL.Scheme+"://"+req.URL.Host]++
+			mu.Unlock()
+			return mockhttp.NewResponseMock(status(req.URL.Host), nil, nil).MakeResponse(req), nil
+		})
+		return client, func() map[string]int {
+			mu.Lock()
+			defer mu.Unlock()
+			return counts
+		}
+	}
+
+	t.Run("Distribution", func(t *testing.T) {
+		recorder, counts := newRecorder(func(string) int { return http.StatusOK })
+		client, err := NewRoundRobinClient(recorder, "http://a.example.com", "http://b.example.com", "https://c.example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 30; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := mustNewRequest(t, http.MethodGet, "http://example.com/path?q=1", nil)
+				res, err := client.Do(req)
+				if err != nil {
+					t.Errorf("Unexpected error is occurred: %#v", err)
+					return
+				}
+				if u := res.Request.URL; u.Path != "/path" || u.RawQuery != "q=1" {
+					t.Errorf("Path and query should be kept, but got: %s", u)
+				}
+				if req.URL.Host != "example.com" {
+					t.Errorf("Original request should not be modified, but got: %s", req.URL)
+				}
+			}()
+		}
+		wg.Wait()
+
+		expected := map[string]int{"http://a.example.com": 10, "http://b.example.com": 10, "https://c.example.com": 10}
+		if diff := cmp.Diff(expected, counts()); diff != "" {
+			t.Errorf("Unexpected distribution: %s", diff)
+		}
+	})
+
+	t.Run("FailureCooldown", func(t *testing.T) {
+		recorder, counts := newRecorder(func(host string) int {
+			if host == "b.example.com" {
+				return http.StatusServiceUnavailable
+			}
+			return http.StatusOK
+		})
+		client, err := NewRoundRobinClient(recorder, "http://a.example.com", "http://b.example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		clock := newFakeClock()
+		client.Clock = clock
+		client.FailureCooldown = time.Minute
+
+		doTimes := func(n int) {
+			for i := 0; i < n; i++ {
+				_, err := client.Do(mustNewRequest(t, http.MethodGet, "http://example.com/", nil))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		doTimes(4)
+		expected := map[string]int{"http://a.example.com": 3, "http://b.example.com": 1}
+		if diff := cmp.Diff(expected, counts()); diff != "" {
+			t.Errorf("Failing host should be skipped: %s", diff)
+		}
+
+		clock.Advance(time.Minute)
+		doTimes(2)
+		expected = map[string]int{"http://a.example.com": 4, "http://b.example.com": 2}
+		if diff := cmp.Diff(expected, counts()); diff != "" {
+			t.Errorf("Host should be used again after cooldown: %s", diff)
+		}
+	})
+
+	t.Run("InvalidHost", func(t *testing.T) {
+		for _, hosts := range [][]string{nil, {"example.com"}, {"http://%zz"}} {
+			if _, err := NewRoundRobinClient(http.DefaultClient, hosts...); err == nil {
+				t.Errorf("Should be error for %#v", hosts)
+			}
+		}
+	})
+	t.Run("ZeroValue", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		if _, err := (&RoundRobinClient{}).Do(req); err != ErrNoClient {
+			t.Errorf("Error should be ErrNoClient, but got: %#v", err)
+		}
+		if _, err := (&RoundRobinClient{Client: http.DefaultClient}).Do(req); err != ErrNoHosts {
+			t.Errorf("Error should be ErrNoHosts, but got: %#v", err)
+		}
+	})
+}
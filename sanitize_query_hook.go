@@ -0,0 +1,54 @@
+package httpagent
+
+import (
+	"net/http"
+	"net/url"
+)
+
+const redactedValue = "REDACTED"
+
+type SanitizeQueryHook struct {
+	Hook   RequestHook
+	Params []string
+}
+
+func (h *SanitizeQueryHook) Do(req *http.Request) error {
+	if h.Hook == nil {
+		return nil
+	}
+
+	// pass a clone to keep the real URL intact
+	clone := req.Clone(req.Context())
+	clone.URL = h.sanitizeURL(req.URL)
+
+	err := h.Hook.Do(clone)
+
+	// the hook may have drained and restored the shared body
+	req.Body = clone.Body
+	return err
+}
+
+func (h *SanitizeQueryHook) sanitizeURL(u *url.URL) *url.URL {
+	u2 := *u
+	if u.User != nil {
+		user := *u.User
+		u2.User = &user
+	}
+
+	query := u.Query()
+	masked := false
+	for _, param := range h.Params {
+		values, ok := query[param]
+		if !ok {
+			continue
+		}
+		for i := range values {
+			values[i] = redactedValue
+		}
+		masked = true
+	}
+	if masked {
+		u2.RawQuery = query.Encode()
+	}
+	return &u2
+}
@@ -0,0 +1,74 @@
+package httpagent
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeQueryHook(t *testing.T) {
+	t.Run("Dumper", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		hook := &SanitizeQueryHook{
+			Hook:   &RequestDumperHook{Writer: buf},
+			Params: []string{"token"},
+		}
+
+		req := mustNewRequest(t, http.MethodPost, "http://example.com/path?token=secret&q=go", strings.NewReader("body"))
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dump := buf.String()
+		if strings.Contains(dump, "secret") {
+			t.Errorf("Dump should not contain the token, but got: %s", dump)
+		}
+		if !strings.HasPrefix(dump, "POST /path?q=go&token=REDACTED") {
+			t.Errorf("Unexpected dump: %s", dump)
+		}
+
+		if token := req.URL.Query().Get("token"); token != "secret" {
+			t.Errorf("Request URL should be intact, but got: %s", req.URL)
+		}
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "body" {
+			t.Errorf("Request body should be intact, but got: %q", s)
+		}
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		var got string
+		hook := &SanitizeQueryHook{
+			Hook: RequestHookFunc(func(req *http.Request) error {
+				got = req.URL.String()
+				return nil
+			}),
+			Params: []string{"token"},
+		}
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/?b=1&a=2", nil)
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "http://example.com/?b=1&a=2" {
+			t.Errorf("URL should be untouched, but got: %s", got)
+		}
+	})
+	t.Run("NilHook", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/?token=secret", nil)
+		err := (&SanitizeQueryHook{Params: []string{"token"}}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := req.URL.String(); s != "http://example.com/?token=secret" {
+			t.Errorf("URL should be untouched, but got: %s", s)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package httpagent
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+const DefaultSequenceHeader = "X-Seq"
+
+type SequenceHook struct {
+	Header string
+	seq    int64
+}
+
+func (h *SequenceHook) Do(req *http.Request) error {
+	header := h.Header
+	if header == "" {
+		header = DefaultSequenceHeader
+	}
+
+	seq := atomic.AddInt64(&h.seq, 1)
+	req.Header.Set(header, strconv.FormatInt(seq, 10))
+	return nil
+}
@@ -0,0 +1,78 @@
+package httpagent
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSequenceHook(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		hook := &SequenceHook{}
+		for i := 1; i <= 3; i++ {
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+			err := hook.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seq := req.Header.Get("X-Seq"); seq != strconv.Itoa(i) {
+				t.Errorf("X-Seq header should be %d, but got: %#v", i, req.Header)
+			}
+		}
+	})
+
+	t.Run("CustomHeader", func(t *testing.T) {
+		hook := &SequenceHook{Header: "Request-Seq"}
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := hook.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq := req.Header.Get("Request-Seq"); seq != "1" {
+			t.Errorf("Request-Seq header should be 1, but got: %#v", req.Header)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		const n = 100
+
+		var mu sync.Mutex
+		seen := map[string]struct{}{}
+
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			seen[req.Header.Get("X-Seq")] = struct{}{}
+			mu.Unlock()
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}))
+		agent.RequestHooks.Append(&SequenceHook{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req, err := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				_, err = agent.Do(req)
+				if err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(seen) != n {
+			t.Errorf("Sequence values should be unique, but got %d values", len(seen))
+		}
+		for i := 1; i <= n; i++ {
+			if _, ok := seen[strconv.Itoa(i)]; !ok {
+				t.Errorf("Sequence value %d is missing", i)
+			}
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package httpagent
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// ServerIdentity describes what served the response.
+// The certificate fields are empty for plaintext responses.
+type ServerIdentity struct {
+	Server      string
+	TLS         bool
+	Subject     string
+	Issuer      string
+	DNSNames    []string
+	IPAddresses []net.IP
+}
+
+type serverIdentityContextKeyType struct{}
+
+var serverIdentityContextKey = serverIdentityContextKeyType{}
+
+func ServerIdentityFromContext(ctx context.Context) *ServerIdentity {
+	identity, _ := ctx.Value(serverIdentityContextKey).(*ServerIdentity)
+	return identity
+}
+
+type ServerIdentityHook struct{}
+
+func (h *ServerIdentityHook) Do(res *http.Response) error {
+	identity := &ServerIdentity{Server: res.Header.Get("Server")}
+	if res.TLS != nil {
+		identity.TLS = true
+		if len(res.TLS.PeerCertificates) != 0 {
+			leaf := res.TLS.PeerCertificates[0]
+			identity.Subject = leaf.Subject.String()
+			identity.Issuer = leaf.Issuer.String()
+			identity.DNSNames = leaf.DNSNames
+			identity.IPAddresses = leaf.IPAddresses
+		}
+	}
+
+	setResponseContextValue(res, serverIdentityContextKey, identity)
+	return nil
+}
@@ -0,0 +1,61 @@
+package httpagent
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestServerIdentityHook(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "test-server/1.0")
+	})
+
+	do := func(t *testing.T, client Client, url string) *ServerIdentity {
+		agent := NewAgent(client)
+		agent.ResponseHooks.Append(&ServerIdentityHook{})
+
+		res, err := agent.Do(mustNewRequest(t, http.MethodGet, url, nil))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+		return ServerIdentityFromContext(res.Request.Context())
+	}
+
+	t.Run("TLS", func(t *testing.T) {
+		ts := httptest.NewTLSServer(handler)
+		t.Cleanup(ts.Close)
+
+		leaf := ts.Certificate()
+		expected := &ServerIdentity{
+			Server:      "test-server/1.0",
+			TLS:         true,
+			Subject:     "O=Acme Co",
+			Issuer:      "O=Acme Co",
+			DNSNames:    leaf.DNSNames,
+			IPAddresses: leaf.IPAddresses,
+		}
+		if len(leaf.DNSNames) == 0 || leaf.DNSNames[0] != "example.com" || len(leaf.IPAddresses) == 0 || !leaf.IPAddresses[0].Equal(net.IPv4(127, 0, 0, 1)) {
+			t.Fatalf("Unexpected certificate of the test server: %#v %#v", leaf.DNSNames, leaf.IPAddresses)
+		}
+
+		identity := do(t, ts.Client(), ts.URL)
+		if diff := cmp.Diff(expected, identity); diff != "" {
+			t.Errorf("Unexpected identity: %s", diff)
+		}
+	})
+
+	t.Run("Plaintext", func(t *testing.T) {
+		ts := httptest.NewServer(handler)
+		t.Cleanup(ts.Close)
+
+		identity := do(t, ts.Client(), ts.URL)
+		if diff := cmp.Diff(&ServerIdentity{Server: "test-server/1.0"}, identity); diff != "" {
+			t.Errorf("Unexpected identity: %s", diff)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ServerTiming struct {
+	Name        string
+	Duration    time.Duration
+	Description string
+}
+
+type serverTimingContextKeyType struct{}
+
+var serverTimingContextKey = serverTimingContextKeyType{}
+
+func ServerTimingFromContext(ctx context.Context) []ServerTiming {
+	timings, _ := ctx.Value(serverTimingContextKey).([]ServerTiming)
+	return timings
+}
+
+type ServerTimingHook struct{}
+
+func (h *ServerTimingHook) Do(res *http.Response) error {
+	var timings []ServerTiming
+	for _, value := range res.Header.Values("Server-Timing") {
+		timings = append(timings, parseServerTiming(value)...)
+	}
+
+	setResponseContextValue(res, serverTimingContextKey, timings)
+	return nil
+}
+
+func parseServerTiming(value string) (timings []ServerTiming) {
+	for _, metric := range splitQuoted(value, ',') {
+		params := splitQuoted(metric, ';')
+		if params[0] == "" {
+			continue
+		}
+
+		timing := ServerTiming{Name: params[0]}
+		for _, param := range params[1:] {
+			key, value := param, ""
+			if i := strings.IndexByte(param, '='); i != -1 {
+				key, value = strings.TrimSpace(param[:i]), unquote(strings.TrimSpace(param[i+1:]))
+			}
+
+			switch strings.ToLower(key) {
+			case "dur":
+				ms, err := strconv.ParseFloat(value, 64)
+				if err == nil {
+					timing.Duration = time.Duration(ms * float64(time.Millisecond))
+				}
+			case "desc":
+				timing.Description = value
+			}
+		}
+		timings = append(timings, timing)
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestServerTimingHook(t *testing.T) {
+	t.Run("MultiMetric", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Add("Server-Timing", `cache;desc="Cache Read";dur=23.2, db;dur=53`)
+		res.Header.Add("Server-Timing", `miss, app;desc="a, b; c"`)
+
+		err := (&ServerTimingHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []ServerTiming{
+			{Name: "cache", Duration: 23200 * time.Microsecond, Description: "Cache Read"},
+			{Name: "db", Duration: 53 * time.Millisecond},
+			{Name: "miss"},
+			{Name: "app", Description: "a, b; c"},
+		}
+		if diff := cmp.Diff(expected, ServerTimingFromContext(res.Request.Context())); diff != "" {
+			t.Errorf("Unexpected server timings: %s", diff)
+		}
+	})
+
+	t.Run("NoHeader", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+
+		err := (&ServerTimingHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if timings := ServerTimingFromContext(res.Request.Context()); len(timings) != 0 {
+			t.Errorf("Server timings should be empty, but got: %#v", timings)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package httpagent
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type SniffGzipResponseHook struct{}
+
+func (h *SniffGzipResponseHook) Do(res *http.Response) error {
+	if res.Body == nil || res.Body == http.NoBody {
+		return nil
+	}
+
+	// trust the declared encoding if any
+	if res.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+
+	br := bufio.NewReader(res.Body)
+	magic, _ := br.Peek(len(gzipMagic))
+	if !bytes.Equal(magic, gzipMagic) {
+		// keep the peeked bytes
+		res.Body = &readCloser{Reader: br, Closer: res.Body}
+		return nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return err
+	}
+
+	res.Body = countDecompressed(res, &gzipReadCloser{Reader: zr, body: res.Body})
+	res.ContentLength = -1
+	res.Header.Del("Content-Length")
+	res.Uncompressed = true
+	return nil
+}
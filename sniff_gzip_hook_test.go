@@ -0,0 +1,110 @@
+package httpagent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func mustGzip(t *testing.T, b []byte) []byte {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniffGzipResponseHook(t *testing.T) {
+	newResponse := func(t *testing.T, headers map[string]string, body []byte) *http.Response {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		return mockhttp.NewResponseMock(http.StatusOK, headers, body).MakeResponse(req)
+	}
+
+	t.Run("Gzip", func(t *testing.T) {
+		res := newResponse(t, nil, mustGzip(t, []byte("hello gzip")))
+		err := (&SniffGzipResponseHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "hello gzip" {
+			t.Errorf("Body should be decompressed, but got: %q", s)
+		}
+		if res.ContentLength != -1 {
+			t.Errorf("ContentLength should be -1, but got: %d", res.ContentLength)
+		}
+		if !res.Uncompressed {
+			t.Errorf("Uncompressed should be true")
+		}
+		err = res.Body.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Plain", func(t *testing.T) {
+		res := newResponse(t, nil, []byte("hello plain"))
+		err := (&SniffGzipResponseHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "hello plain" {
+			t.Errorf("Body should be untouched, but got: %q", s)
+		}
+		if res.ContentLength != 11 {
+			t.Errorf("ContentLength should be 11, but got: %d", res.ContentLength)
+		}
+	})
+
+	t.Run("Short", func(t *testing.T) {
+		res := newResponse(t, nil, []byte{0x1f})
+		err := (&SniffGzipResponseHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, []byte{0x1f}) {
+			t.Errorf("Body should be untouched, but got: %#v", b)
+		}
+	})
+
+	t.Run("DeclaredEncoding", func(t *testing.T) {
+		body := mustGzip(t, []byte("hello gzip"))
+		res := newResponse(t, map[string]string{"Content-Encoding": "gzip"}, body)
+		err := (&SniffGzipResponseHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, body) {
+			t.Errorf("Body should be untouched, but got: %#v", b)
+		}
+	})
+}
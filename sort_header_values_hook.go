@@ -0,0 +1,32 @@
+package httpagent
+
+import (
+	"net/http"
+	"sort"
+)
+
+type SortHeaderValuesHook struct {
+	Headers []string
+}
+
+func (h *SortHeaderValuesHook) Do(req *http.Request) error {
+	for _, name := range h.Headers {
+		key := http.CanonicalHeaderKey(name)
+		values, ok := req.Header[key]
+		if !ok {
+			continue
+		}
+
+		sorted := append([]string(nil), values...)
+		sort.Strings(sorted)
+
+		deduped := sorted[:0]
+		for i, value := range sorted {
+			if i == 0 || value != sorted[i-1] {
+				deduped = append(deduped, value)
+			}
+		}
+		req.Header[key] = deduped
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSortHeaderValuesHook(t *testing.T) {
+	req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+	for _, v := range []string{"gzip", "br", "deflate", "br"} {
+		req.Header.Add("Accept-Encoding", v)
+	}
+	for _, v := range []string{"b", "a"} {
+		req.Header.Add("X-Untouched", v)
+	}
+
+	err := (&SortHeaderValuesHook{Headers: []string{"accept-encoding", "X-Missing"}}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := req.Header.Values("Accept-Encoding"); !cmp.Equal(v, []string{"br", "deflate", "gzip"}) {
+		t.Errorf("Accept-Encoding should be canonical, but got: %#v", v)
+	}
+	if v := req.Header.Values("X-Untouched"); !cmp.Equal(v, []string{"b", "a"}) {
+		t.Errorf("X-Untouched should be untouched, but got: %#v", v)
+	}
+	if _, ok := req.Header["X-Missing"]; ok {
+		t.Errorf("X-Missing should not be added, but got: %#v", req.Header)
+	}
+}
@@ -0,0 +1,65 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SortQueryHook re-encodes the query with the keys and the values of each key sorted.
+// The query which cannot be parsed is left as is. Use ContextWithoutSortQuery for the endpoints where the order matters.
+type SortQueryHook struct{}
+
+func (h *SortQueryHook) Do(req *http.Request) error {
+	if req.URL.RawQuery == "" || contextWithoutSortQuery(req.Context()) {
+		return nil
+	}
+
+	query, err := url.ParseQuery(req.URL.RawQuery)
+	if err != nil {
+		return nil
+	}
+	req.URL.RawQuery = encodeSortedQuery(query)
+	return nil
+}
+
+func encodeSortedQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+
+		escapedKey := url.QueryEscape(key)
+		for _, value := range values {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(escapedKey)
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(value))
+		}
+	}
+	return b.String()
+}
+
+type withoutSortQueryContextKeyType struct{}
+
+var withoutSortQueryContextKey = withoutSortQueryContextKeyType{}
+
+// ContextWithoutSortQuery makes SortQueryHook keep the order of the query.
+func ContextWithoutSortQuery(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutSortQueryContextKey, true)
+}
+
+func contextWithoutSortQuery(ctx context.Context) bool {
+	without, _ := ctx.Value(withoutSortQueryContextKey).(bool)
+	return without
+}
@@ -0,0 +1,46 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSortQueryHook(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "Scrambled", query: "b=2&c=3&a=1", expected: "a=1&b=2&c=3"},
+		{name: "RepeatedValues", query: "tag=z&id=1&tag=a&tag=m", expected: "id=1&tag=a&tag=m&tag=z"},
+		{name: "Escaped", query: "q=hello+world&a=%26", expected: "a=%26&q=hello+world"},
+		{name: "EmptyValue", query: "b&a=", expected: "a=&b="},
+		{name: "Empty", query: "", expected: ""},
+		{name: "Invalid", query: "b=%zz&a=1", expected: "b=%zz&a=1"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			req := mustNewRequest(t, http.MethodGet, "http://example.com/?"+c.query, nil)
+			err := (&SortQueryHook{}).Do(req)
+			if err != nil {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			if req.URL.RawQuery != c.expected {
+				t.Errorf("Query should be %s, but got: %s", c.expected, req.URL.RawQuery)
+			}
+		})
+	}
+
+	t.Run("OptOut", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/?b=2&a=1", nil)
+		req = req.WithContext(ContextWithoutSortQuery(req.Context()))
+		err := (&SortQueryHook{}).Do(req)
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if req.URL.RawQuery != "b=2&a=1" {
+			t.Errorf("Query should be kept, but got: %s", req.URL.RawQuery)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package httpagent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+
+	// Body is the head of the response body for diagnostics.
+	Body []byte
+}
+
+func (e *HTTPStatusError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("httpagent: unexpected status: %s", e.Status)
+	}
+	return fmt.Sprintf("httpagent: unexpected status: %s: %q", e.Status, e.Body)
+}
+
+func isSuccessStatus(status int) bool {
+	return status >= 200 && status < 300
+}
+
+type StatusErrorHook struct {
+	// Accept reports whether the status is accepted. (default: 2xx)
+	Accept func(int) bool
+
+	// MaxBodyBytes is the maximum bytes of the body captured into HTTPStatusError.
+	MaxBodyBytes int
+}
+
+func (h *StatusErrorHook) Do(res *http.Response) error {
+	accept := h.Accept
+	if accept == nil {
+		accept = isSuccessStatus
+	}
+	if accept(res.StatusCode) {
+		return nil
+	}
+
+	statusErr := &HTTPStatusError{StatusCode: res.StatusCode, Status: res.Status}
+	if h.MaxBodyBytes > 0 && res.Body != nil && res.Body != http.NoBody {
+		head, err := io.ReadAll(io.LimitReader(res.Body, int64(h.MaxBodyBytes)))
+		res.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(head), res.Body), Closer: res.Body}
+		if err != nil {
+			return err
+		}
+		statusErr.Body = head
+	}
+	return statusErr
+}
@@ -0,0 +1,78 @@
+package httpagent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestStatusErrorHook(t *testing.T) {
+	newResponse := func(t *testing.T, status int, body string) *http.Response {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		return mockhttp.NewResponseMock(status, nil, []byte(body)).MakeResponse(req)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		for _, status := range []int{http.StatusOK, http.StatusNoContent} {
+			err := (&StatusErrorHook{}).Do(newResponse(t, status, ""))
+			if err != nil {
+				t.Errorf("Unexpected error is occurred for %d: %#v", status, err)
+			}
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		res := newResponse(t, http.StatusNotFound, "not found: /foo")
+		err := (&StatusErrorHook{MaxBodyBytes: 9}).Do(res)
+
+		var statusErr *HTTPStatusError
+		if !errors.As(fmt.Errorf("wrapped: %w", err), &statusErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if statusErr.StatusCode != http.StatusNotFound || statusErr.Status != "404 Not Found" {
+			t.Errorf("Unexpected status: %#v", statusErr)
+		}
+		if s := string(statusErr.Body); s != "not found" {
+			t.Errorf("Body should be not found, but got: %s", s)
+		}
+
+		b, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(b); s != "not found: /foo" {
+			t.Errorf("Body should be preserved, but got: %s", s)
+		}
+	})
+
+	t.Run("NoBody", func(t *testing.T) {
+		res := newResponse(t, http.StatusInternalServerError, "oops")
+		err := (&StatusErrorHook{}).Do(res)
+
+		var statusErr *HTTPStatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		if statusErr.Body != nil {
+			t.Errorf("Body should not be captured, but got: %s", statusErr.Body)
+		}
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		hook := &StatusErrorHook{Accept: func(status int) bool {
+			return status < 500
+		}}
+		if err := hook.Do(newResponse(t, http.StatusNotFound, "")); err != nil {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+
+		var statusErr *HTTPStatusError
+		if err := hook.Do(newResponse(t, http.StatusBadGateway, "")); !errors.As(err, &statusErr) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+}
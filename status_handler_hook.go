@@ -0,0 +1,28 @@
+package httpagent
+
+import (
+	"net/http"
+)
+
+type StatusHandlerFunc func(*http.Response) error
+
+// StatusHandlerHook dispatches the response to the handler for the exact status code,
+// the status class (e.g. 4 for 4xx), or the default handler in this order.
+type StatusHandlerHook struct {
+	Handlers      map[int]StatusHandlerFunc
+	ClassHandlers map[int]StatusHandlerFunc
+	Default       StatusHandlerFunc
+}
+
+func (h *StatusHandlerHook) Do(res *http.Response) error {
+	if handler, ok := h.Handlers[res.StatusCode]; ok {
+		return handler(res)
+	}
+	if handler, ok := h.ClassHandlers[res.StatusCode/100]; ok {
+		return handler(res)
+	}
+	if h.Default != nil {
+		return h.Default(res)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package httpagent
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+type testNotFoundError struct {
+	URL string
+}
+
+func (e *testNotFoundError) Error() string {
+	return "not found: " + e.URL
+}
+
+func TestStatusHandlerHook(t *testing.T) {
+	errServer := errors.New("server error")
+	errDefault := errors.New("default")
+	hook := &StatusHandlerHook{
+		Handlers: map[int]StatusHandlerFunc{
+			http.StatusNotFound: func(res *http.Response) error {
+				return &testNotFoundError{URL: res.Request.URL.String()}
+			},
+			http.StatusOK: func(res *http.Response) error {
+				return nil
+			},
+		},
+		ClassHandlers: map[int]StatusHandlerFunc{
+			5: func(res *http.Response) error {
+				return errServer
+			},
+		},
+		Default: func(res *http.Response) error {
+			return errDefault
+		},
+	}
+
+	do := func(t *testing.T, status int) error {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		res := mockhttp.NewResponseMock(status, nil, nil).MakeResponse(req)
+		return hook.Do(res)
+	}
+
+	t.Run("NotFound", func(t *testing.T) {
+		var notFound *testNotFoundError
+		if err := do(t, http.StatusNotFound); !errors.As(err, &notFound) {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		} else if notFound.URL != "http://example.com/" {
+			t.Errorf("Unexpected URL: %s", notFound.URL)
+		}
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		if err := do(t, http.StatusOK); err != nil {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("Class", func(t *testing.T) {
+		if err := do(t, http.StatusBadGateway); err != errServer {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		if err := do(t, http.StatusAccepted); err != errDefault {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+
+	t.Run("NoHandler", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		res := mockhttp.NewResponseMock(http.StatusTeapot, nil, nil).MakeResponse(req)
+		if err := (&StatusHandlerHook{}).Do(res); err != nil {
+			t.Errorf("Unexpected error is occurred: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package httpagent
+
+import (
+	"net/http"
+	"strings"
+)
+
+// StripDefaultPortHook removes the default port of the scheme (":80" for http and ":443" for https) from the URL host and the Host header.
+type StripDefaultPortHook struct{}
+
+func (h *StripDefaultPortHook) Do(req *http.Request) error {
+	req.URL.Host = stripDefaultPort(req.URL.Scheme, req.URL.Host)
+	if req.Host != "" {
+		req.Host = stripDefaultPort(req.URL.Scheme, req.Host)
+	}
+	return nil
+}
+
+func stripDefaultPort(scheme, host string) string {
+	var port string
+	switch strings.ToLower(scheme) {
+	case "http":
+		port = ":80"
+	case "https":
+		port = ":443"
+	default:
+		return host
+	}
+	return strings.TrimSuffix(host, port)
+}
@@ -0,0 +1,41 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestStripDefaultPortHook(t *testing.T) {
+	cases := []struct {
+		name     string
+		url      string
+		host     string
+		expected string
+	}{
+		{name: "HTTP", url: "http://example.com:80/path", expected: "example.com"},
+		{name: "HTTPS", url: "https://example.com:443/path", expected: "example.com"},
+		{name: "IPv6", url: "http://[::1]:80/path", expected: "[::1]"},
+		{name: "CustomPort", url: "http://example.com:8080/path", expected: "example.com:8080"},
+		{name: "OtherSchemePort", url: "http://example.com:443/path", expected: "example.com:443"},
+		{name: "NoPort", url: "https://example.com/path", expected: "example.com"},
+		{name: "HostHeader", url: "https://example.com:443/path", host: "api.example.com:443", expected: "example.com"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			req := mustNewRequest(t, http.MethodGet, c.url, nil)
+			req.Host = c.host
+
+			err := (&StripDefaultPortHook{}).Do(req)
+			if err != nil {
+				t.Fatalf("Unexpected error is occurred: %#v", err)
+			}
+			if req.URL.Host != c.expected {
+				t.Errorf("URL host should be %s, but got: %s", c.expected, req.URL.Host)
+			}
+			if c.host != "" && req.Host != "api.example.com" {
+				t.Errorf("Host should be api.example.com, but got: %s", req.Host)
+			}
+		})
+	}
+}
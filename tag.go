@@ -0,0 +1,28 @@
+package httpagent
+
+import "context"
+
+type tagsContextKeyType struct{}
+
+var tagsContextKey = tagsContextKeyType{}
+
+// ContextWithTag tags the requests with the context to classify them in the hooks.
+// Tags are never sent to the server.
+func ContextWithTag(ctx context.Context, key, value string) context.Context {
+	parent, _ := ctx.Value(tagsContextKey).(map[string]string)
+	tags := make(map[string]string, len(parent)+1)
+	for k, v := range parent {
+		tags[k] = v
+	}
+	tags[key] = value
+	return context.WithValue(ctx, tagsContextKey, tags)
+}
+
+func TagsFromContext(ctx context.Context) map[string]string {
+	parent, _ := ctx.Value(tagsContextKey).(map[string]string)
+	tags := make(map[string]string, len(parent))
+	for k, v := range parent {
+		tags[k] = v
+	}
+	return tags
+}
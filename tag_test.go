@@ -0,0 +1,59 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mockhttp "github.com/karupanerura/go-mock-http-response"
+)
+
+func TestContextWithTag(t *testing.T) {
+	parent := ContextWithTag(context.Background(), "priority", "background")
+	child := ContextWithTag(parent, "priority", "critical")
+	child = ContextWithTag(child, "team", "search")
+
+	if diff := cmp.Diff(map[string]string{"priority": "background"}, TagsFromContext(parent)); diff != "" {
+		t.Errorf("Tags of the parent should not be changed: %s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"priority": "critical", "team": "search"}, TagsFromContext(child)); diff != "" {
+		t.Errorf("Unexpected tags: %s", diff)
+	}
+
+	TagsFromContext(child)["team"] = "modified"
+	if tags := TagsFromContext(child); tags["team"] != "search" {
+		t.Errorf("Modifying the returned tags should not affect the context, but got: %#v", tags)
+	}
+
+	if tags := TagsFromContext(context.Background()); len(tags) != 0 {
+		t.Errorf("Tags should be empty, but got: %#v", tags)
+	}
+
+	t.Run("ResponseHook", func(t *testing.T) {
+		var header http.Header
+		var tags map[string]string
+		agent := NewAgent(ClientFunc(func(req *http.Request) (*http.Response, error) {
+			header = req.Header
+			return mockhttp.NewResponseMock(http.StatusOK, nil, nil).MakeResponse(req), nil
+		}))
+		agent.ResponseHooks.Append(ResponseHookFunc(func(res *http.Response) error {
+			tags = TagsFromContext(res.Request.Context())
+			return nil
+		}))
+
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		res, err := agent.Do(req.WithContext(ContextWithTag(req.Context(), "priority", "critical")))
+		if err != nil {
+			t.Fatalf("Unexpected error is occurred: %#v", err)
+		}
+		res.Body.Close()
+
+		if diff := cmp.Diff(map[string]string{"priority": "critical"}, tags); diff != "" {
+			t.Errorf("Unexpected tags: %s", diff)
+		}
+		if len(header) != 0 {
+			t.Errorf("Tags should not be sent, but got: %#v", header)
+		}
+	})
+}
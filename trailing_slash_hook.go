@@ -0,0 +1,46 @@
+package httpagent
+
+import (
+	"net/http"
+	"strings"
+)
+
+type TrailingSlashMode int
+
+const (
+	TrailingSlashPreserve TrailingSlashMode = iota
+	TrailingSlashAdd
+	TrailingSlashRemove
+)
+
+type TrailingSlashHook struct {
+	Mode TrailingSlashMode
+}
+
+func (h *TrailingSlashHook) Do(req *http.Request) error {
+	u := req.URL
+	if u.Opaque != "" || u.Path == "" || u.Path == "/" {
+		return nil
+	}
+
+	u.Path = h.normalize(u.Path)
+	if u.RawPath != "" {
+		u.RawPath = h.normalize(u.RawPath)
+	}
+	return nil
+}
+
+func (h *TrailingSlashHook) normalize(path string) string {
+	switch h.Mode {
+	case TrailingSlashAdd:
+		if !strings.HasSuffix(path, "/") {
+			return path + "/"
+		}
+	case TrailingSlashRemove:
+		if trimmed := strings.TrimRight(path, "/"); trimmed != "" {
+			return trimmed
+		}
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,39 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTrailingSlashHook(t *testing.T) {
+	cases := []struct {
+		name     string
+		mode     TrailingSlashMode
+		url      string
+		expected string
+	}{
+		{name: "Preserve/NoSlash", mode: TrailingSlashPreserve, url: "http://example.com/foo", expected: "/foo"},
+		{name: "Preserve/Slash", mode: TrailingSlashPreserve, url: "http://example.com/foo/", expected: "/foo/"},
+		{name: "Add/NoSlash", mode: TrailingSlashAdd, url: "http://example.com/foo?a=1", expected: "/foo/?a=1"},
+		{name: "Add/Slash", mode: TrailingSlashAdd, url: "http://example.com/foo/", expected: "/foo/"},
+		{name: "Add/Root", mode: TrailingSlashAdd, url: "http://example.com", expected: "/"},
+		{name: "Add/Escaped", mode: TrailingSlashAdd, url: "http://example.com/a%2Fb", expected: "/a%2Fb/"},
+		{name: "Remove/NoSlash", mode: TrailingSlashRemove, url: "http://example.com/foo", expected: "/foo"},
+		{name: "Remove/Slash", mode: TrailingSlashRemove, url: "http://example.com/foo/?a=1", expected: "/foo?a=1"},
+		{name: "Remove/Slashes", mode: TrailingSlashRemove, url: "http://example.com/foo//", expected: "/foo"},
+		{name: "Remove/Root", mode: TrailingSlashRemove, url: "http://example.com/", expected: "/"},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			req := mustNewRequest(t, http.MethodGet, c.url, nil)
+			err := (&TrailingSlashHook{Mode: c.mode}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uri := req.URL.RequestURI(); uri != c.expected {
+				t.Errorf("RequestURI should be %s, but got: %s", c.expected, uri)
+			}
+		})
+	}
+}
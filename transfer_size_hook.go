@@ -0,0 +1,84 @@
+package httpagent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// TransferSize is an estimate of bytes on the wire for HTTP/1.1.
+// It does not consider the transfer encoding, compression by the transport, or HTTP/2 framing.
+type TransferSize struct {
+	headerBytes int64
+	bodyBytes   int64
+	done        int32
+}
+
+func (s *TransferSize) HeaderBytes() int64 {
+	return s.headerBytes
+}
+
+func (s *TransferSize) BodyBytes() int64 {
+	return atomic.LoadInt64(&s.bodyBytes)
+}
+
+func (s *TransferSize) Total() int64 {
+	return s.HeaderBytes() + s.BodyBytes()
+}
+
+// Done reports whether the body has been read to the end.
+func (s *TransferSize) Done() bool {
+	return atomic.LoadInt32(&s.done) == 1
+}
+
+type transferSizeContextKeyType struct{}
+
+var transferSizeContextKey = transferSizeContextKeyType{}
+
+func TransferSizeFromContext(ctx context.Context) *TransferSize {
+	size, _ := ctx.Value(transferSizeContextKey).(*TransferSize)
+	return size
+}
+
+type TransferSizeHook struct{}
+
+func (h *TransferSizeHook) Do(res *http.Response) error {
+	counter := &countWriter{}
+	_, _ = io.WriteString(counter, res.Proto+" "+res.Status+"\r\n")
+	_ = res.Header.Write(counter)
+	_, _ = io.WriteString(counter, "\r\n")
+
+	size := &TransferSize{headerBytes: counter.n}
+	if res.Body == nil || res.Body == http.NoBody {
+		size.done = 1
+	} else {
+		res.Body = &transferSizeReadCloser{ReadCloser: res.Body, size: size}
+	}
+
+	setResponseContextValue(res, transferSizeContextKey, size)
+	return nil
+}
+
+type countWriter struct {
+	n int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+type transferSizeReadCloser struct {
+	io.ReadCloser
+	size *TransferSize
+}
+
+func (r *transferSizeReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	atomic.AddInt64(&r.size.bodyBytes, int64(n))
+	if err == io.EOF {
+		atomic.StoreInt32(&r.size.done, 1)
+	}
+	return n, err
+}
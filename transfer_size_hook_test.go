@@ -0,0 +1,45 @@
+package httpagent
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestTransferSizeHook(t *testing.T) {
+	res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+	err := (&TransferSizeHook{}).Do(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	size := TransferSizeFromContext(res.Request.Context())
+	if size == nil {
+		t.Fatal("TransferSize should be stored in context")
+	}
+	if size.Done() {
+		t.Error("Should not be done before reading the body")
+	}
+
+	_, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !size.Done() {
+		t.Error("Should be done after reading the body")
+	}
+
+	header := "HTTP/1.0 200 OK\r\n" +
+		"Content-Length: 2\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n"
+	if size.HeaderBytes() != int64(len(header)) {
+		t.Errorf("HeaderBytes should be %d, but got: %d", len(header), size.HeaderBytes())
+	}
+	if size.BodyBytes() != 2 {
+		t.Errorf("BodyBytes should be 2, but got: %d", size.BodyBytes())
+	}
+	if size.Total() != int64(len(header)+2) {
+		t.Errorf("Total should be %d, but got: %d", len(header)+2, size.Total())
+	}
+}
@@ -0,0 +1,61 @@
+package httpagent
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+const DefaultUserAgent = "go-httpagent"
+
+const modulePath = "github.com/karupanerura/go-httpagent"
+
+var (
+	moduleVersionOnce sync.Once
+	moduleVersionText string
+)
+
+func moduleVersion() string {
+	moduleVersionOnce.Do(func() {
+		moduleVersionText = "(devel)"
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				moduleVersionText = dep.Version
+				return
+			}
+		}
+	})
+	return moduleVersionText
+}
+
+type UserAgentHook struct {
+	UserAgent string
+
+	// AppendVersion appends the package version and the Go version. (e.g. "myapp/1.0 go-httpagent/v1.2.3 go1.18")
+	AppendVersion bool
+	SkipIfExists  bool
+}
+
+func (h *UserAgentHook) Do(req *http.Request) error {
+	if h.SkipIfExists {
+		if _, ok := req.Header["User-Agent"]; ok {
+			return nil
+		}
+	}
+
+	userAgent := h.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	if h.AppendVersion {
+		userAgent += " " + DefaultUserAgent + "/" + moduleVersion() + " " + runtime.Version()
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+	return nil
+}
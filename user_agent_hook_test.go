@@ -0,0 +1,58 @@
+package httpagent
+
+import (
+	"net/http"
+	"runtime"
+	"testing"
+)
+
+func TestUserAgentHook(t *testing.T) {
+	t.Run("Set", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("User-Agent", "curl/7.0")
+		err := (&UserAgentHook{UserAgent: "myapp/1.0"}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ua := req.Header.Get("User-Agent"); ua != "myapp/1.0" {
+			t.Errorf("User-Agent should be myapp/1.0, but got: %s", ua)
+		}
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&UserAgentHook{}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ua := req.Header.Get("User-Agent"); ua != DefaultUserAgent {
+			t.Errorf("User-Agent should be %s, but got: %s", DefaultUserAgent, ua)
+		}
+	})
+
+	t.Run("AppendVersion", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		err := (&UserAgentHook{UserAgent: "myapp/1.0", AppendVersion: true}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the module is the main module in the test, so its version is unknown
+		expected := "myapp/1.0 go-httpagent/(devel) " + runtime.Version()
+		if ua := req.Header.Get("User-Agent"); ua != expected {
+			t.Errorf("User-Agent should be %s, but got: %s", expected, ua)
+		}
+	})
+
+	t.Run("SkipIfExists", func(t *testing.T) {
+		req := mustNewRequest(t, http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("User-Agent", "curl/7.0")
+		err := (&UserAgentHook{UserAgent: "myapp/1.0", SkipIfExists: true}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ua := req.Header.Get("User-Agent"); ua != "curl/7.0" {
+			t.Errorf("User-Agent should be kept, but got: %s", ua)
+		}
+	})
+}
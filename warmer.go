@@ -0,0 +1,97 @@
+package httpagent
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWarmerAlreadyStarted  = errors.New("httpagent: warmer is already started")
+	ErrInvalidWarmerInterval = errors.New("httpagent: warmer interval must be positive")
+)
+
+type Warmer struct {
+	Agent    *Agent
+	URL      string
+	Interval time.Duration
+	Jitter   time.Duration
+	OnError  func(error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (w *Warmer) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return ErrWarmerAlreadyStarted
+	}
+	if w.Interval <= 0 {
+		return ErrInvalidWarmerInterval
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	go w.run(ctx, w.done)
+	return nil
+}
+
+func (w *Warmer) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel == nil {
+		return
+	}
+
+	w.cancel()
+	<-w.done
+	w.cancel = nil
+	w.done = nil
+}
+
+func (w *Warmer) run(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	timer := w.Agent.clock().NewTimer(w.nextInterval())
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+		}
+
+		err := w.warmup(ctx)
+		if err != nil && ctx.Err() == nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		timer.Reset(w.nextInterval())
+	}
+}
+
+func (w *Warmer) nextInterval() time.Duration {
+	if w.Jitter <= 0 {
+		return w.Interval
+	}
+	return w.Interval + time.Duration(rand.Int63n(int64(w.Jitter)))
+}
+
+func (w *Warmer) warmup(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, w.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := w.Agent.Do(req)
+	if err != nil {
+		return err
+	}
+	DrainAndClose(res)
+	return nil
+}
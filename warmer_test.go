@@ -0,0 +1,104 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmer(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("Method should be HEAD, but got: %s", r.Method)
+		}
+		atomic.AddInt32(&count, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+
+	warmer := &Warmer{
+		Agent:    NewAgent(http.DefaultClient),
+		URL:      ts.URL,
+		Interval: 10 * time.Millisecond,
+		Jitter:   5 * time.Millisecond,
+		OnError: func(err error) {
+			t.Error(err)
+		},
+	}
+
+	err := warmer.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := warmer.Start(context.Background()); err != ErrWarmerAlreadyStarted {
+		t.Errorf("Unexpected error is occurred: %#v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for atomic.LoadInt32(&count) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Target should receive periodic requests, but got %d requests", atomic.LoadInt32(&count))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	warmer.Stop()
+	stopped := atomic.LoadInt32(&count)
+	time.Sleep(50 * time.Millisecond)
+	if c := atomic.LoadInt32(&count); c != stopped {
+		t.Errorf("Should not send requests after Stop, but got %d requests (was %d)", c, stopped)
+	}
+
+	// restartable
+	err = warmer.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	warmer.Stop()
+	warmer.Stop()
+}
+
+func TestWarmerWithFakeClock(t *testing.T) {
+	var count int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(ts.Close)
+
+	clock := newFakeClock()
+	agent := NewAgent(http.DefaultClient)
+	agent.Clock = clock
+
+	warmer := &Warmer{Agent: agent, URL: ts.URL, Interval: time.Minute}
+	err := warmer.Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer warmer.Stop()
+
+	for i := int32(1); i <= 3; i++ {
+		clock.WaitTimers(t, 1)
+		clock.Advance(time.Minute)
+
+		deadline := time.Now().Add(3 * time.Second)
+		for atomic.LoadInt32(&count) < i {
+			if time.Now().After(deadline) {
+				t.Fatalf("Target should receive %d requests, but got %d requests", i, atomic.LoadInt32(&count))
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestWarmerInvalidInterval(t *testing.T) {
+	warmer := &Warmer{Agent: NewAgent(http.DefaultClient), URL: "http://example.com/"}
+	if err := warmer.Start(context.Background()); err != ErrInvalidWarmerInterval {
+		t.Errorf("Unexpected error is occurred: %#v", err)
+	}
+	warmer.Stop()
+}
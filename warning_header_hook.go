@@ -0,0 +1,65 @@
+package httpagent
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type Warning struct {
+	Code  int
+	Agent string
+	Text  string
+	Date  time.Time
+}
+
+type warningContextKeyType struct{}
+
+var warningContextKey = warningContextKeyType{}
+
+func WarningsFromContext(ctx context.Context) []Warning {
+	warnings, _ := ctx.Value(warningContextKey).([]Warning)
+	return warnings
+}
+
+type WarningHeaderHook struct{}
+
+func (h *WarningHeaderHook) Do(res *http.Response) error {
+	var warnings []Warning
+	for _, value := range res.Header.Values("Warning") {
+		warnings = append(warnings, parseWarning(value)...)
+	}
+
+	setResponseContextValue(res, warningContextKey, warnings)
+	return nil
+}
+
+func parseWarning(value string) (warnings []Warning) {
+	for _, entry := range splitQuoted(value, ',') {
+		var fields []string
+		for _, field := range splitQuoted(entry, ' ') {
+			if field != "" {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) < 3 {
+			continue
+		}
+
+		code, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+
+		warning := Warning{Code: code, Agent: fields[1], Text: unquote(fields[2])}
+		if len(fields) > 3 {
+			date, err := http.ParseTime(unquote(fields[3]))
+			if err == nil {
+				warning.Date = date
+			}
+		}
+		warnings = append(warnings, warning)
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package httpagent
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWarningHeaderHook(t *testing.T) {
+	t.Run("MultiWarning", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Add("Warning", `110 anderson/1.3.37 "Response is stale", 112 - "cache down, really" "Wed, 21 Oct 2015 07:28:00 GMT"`)
+		res.Header.Add("Warning", `199 proxy.example.com:8080 "Miscellaneous warning"`)
+
+		err := (&WarningHeaderHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []Warning{
+			{Code: 110, Agent: "anderson/1.3.37", Text: "Response is stale"},
+			{Code: 112, Agent: "-", Text: "cache down, really", Date: time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)},
+			{Code: 199, Agent: "proxy.example.com:8080", Text: "Miscellaneous warning"},
+		}
+		if diff := cmp.Diff(expected, WarningsFromContext(res.Request.Context())); diff != "" {
+			t.Errorf("Unexpected warnings: %s", diff)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		res := mustNewResponse(t, http.MethodGet, "http://example.com/", nil)
+		res.Header.Add("Warning", `abc - "oops", 110 -`)
+
+		err := (&WarningHeaderHook{}).Do(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if warnings := WarningsFromContext(res.Request.Context()); len(warnings) != 0 {
+			t.Errorf("Warnings should be empty, but got: %#v", warnings)
+		}
+	})
+}